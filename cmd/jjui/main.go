@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/context"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -29,6 +30,8 @@ var (
 	version    bool
 	editConfig bool
 	help       bool
+	// applySelection is set when jj runs jjui as the diff editor to apply the changes selected in the hunk selector
+	applySelection string
 )
 
 func init() {
@@ -37,6 +40,7 @@ func init() {
 	flag.BoolVar(&version, "version", false, "Show version information")
 	flag.BoolVar(&editConfig, "config", false, "Open configuration file in $EDITOR")
 	flag.BoolVar(&help, "help", false, "Show help information")
+	flag.StringVar(&applySelection, jj.ApplySelectionFlag, "", "Apply the selected changes to the given directory (used internally as jj's diff editor)")
 
 	flag.Usage = func() {
 		fmt.Printf("Usage: jjui [flags] [location]\n")
@@ -57,6 +61,12 @@ func main() {
 	case editConfig:
 		exitCode := config.Edit()
 		os.Exit(exitCode)
+	case applySelection != "":
+		if err := jj.ApplySelection(applySelection, flag.Arg(0)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
 	}
 
 	var location string
@@ -81,6 +91,10 @@ func main() {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
+	// the layout is saved once at the end rather than on every resize
+	if err := config.SavePreviewLayout(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving the preview layout: %v\n", err)
+	}
 }
 
 func getJJRootDir(location string) (string, error) {
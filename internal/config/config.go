@@ -1,12 +1,18 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/BurntSushi/toml"
 	"log"
+	"math"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 var Current = &Config{
@@ -18,10 +24,16 @@ var Current = &Config{
 	Preview: PreviewConfig{
 		ExtraArgs:   []string{},
 		ShowAtStart: false,
+		Position:    PreviewRight,
+		Ratio:       0.5,
 	},
 	OpLog: OpLogConfig{
 		Limit: 200,
 	},
+	Git: GitConfig{
+		ProtectedBookmarks:   []string{},
+		StackBookmarkPattern: "push-{change_id}",
+	},
 }
 
 type Config struct {
@@ -29,6 +41,8 @@ type Config struct {
 	UI      UIConfig          `toml:"ui"`
 	Preview PreviewConfig     `toml:"preview"`
 	OpLog   OpLogConfig       `toml:"oplog"`
+	Git     GitConfig         `toml:"git"`
+	Diff    DiffConfig        `toml:"diff"`
 }
 
 type UIConfig struct {
@@ -39,12 +53,110 @@ type UIConfig struct {
 type PreviewConfig struct {
 	ExtraArgs   []string `toml:"extra_args"`
 	ShowAtStart bool     `toml:"show_at_start"`
+	// SideBySide shows diffs in two columns when there is enough room; it can be toggled during the session
+	SideBySide bool `toml:"side_by_side"`
+	// DiffFormat is one of DiffFormats, empty uses the format configured in jj
+	DiffFormat       string `toml:"diff_format"`
+	IgnoreWhitespace bool   `toml:"ignore_whitespace"`
+	// ContextLines is the number of context lines around changes, 0 uses jj's default
+	ContextLines int `toml:"context_lines"`
+	// Position is where the preview is placed, PreviewRight or PreviewBottom
+	Position string `toml:"position"`
+	// Ratio is the share of the width (or height when placed at the bottom) the preview takes
+	Ratio float64 `toml:"ratio"`
+}
+
+const (
+	PreviewRight  = "right"
+	PreviewBottom = "bottom"
+
+	minPreviewRatio  = 0.2
+	maxPreviewRatio  = 0.8
+	previewRatioStep = 0.1
+)
+
+func (p *PreviewConfig) TogglePosition() {
+	if p.Position == PreviewBottom {
+		p.Position = PreviewRight
+	} else {
+		p.Position = PreviewBottom
+	}
+}
+
+// SplitRatio is the configured ratio limited to a usable range
+func (p *PreviewConfig) SplitRatio() float64 {
+	return max(minPreviewRatio, min(maxPreviewRatio, p.Ratio))
+}
+
+// Resize grows (or shrinks when negative) the preview by the given number of steps
+func (p *PreviewConfig) Resize(steps int) {
+	ratio := p.SplitRatio() + float64(steps)*previewRatioStep
+	// round to avoid accumulating floating point errors
+	p.Ratio = max(minPreviewRatio, min(maxPreviewRatio, math.Round(ratio*10)/10))
+}
+
+var DiffFormats = []string{"", "git", "color-words", "stat", "summary"}
+
+// ContextLineOptions are the context line counts cycled through during the session
+var ContextLineOptions = []int{0, 1, 5, 10, 25}
+
+func (p *PreviewConfig) CycleDiffFormat() {
+	p.DiffFormat = DiffFormats[(slices.Index(DiffFormats, p.DiffFormat)+1)%len(DiffFormats)]
+}
+
+func (p *PreviewConfig) CycleContextLines() {
+	p.ContextLines = ContextLineOptions[(slices.Index(ContextLineOptions, p.ContextLines)+1)%len(ContextLineOptions)]
+}
+
+type DiffConfig struct {
+	// Formatter is an external program (e.g. delta) that git formatted diffs are piped through before they are shown.
+	// {width} is replaced with the width of the view.
+	Formatter []string `toml:"formatter"`
+	// Tool is passed to `jj diff --tool` for diff tools that can't read from a pipe (e.g. difft)
+	Tool string `toml:"tool"`
+	// Pager is the interactive program the diff is handed to, $PAGER or less when empty
+	Pager []string `toml:"pager"`
+}
+
+func (d DiffConfig) FormatterArgs(width int) []string {
+	args := make([]string, len(d.Formatter))
+	for i, arg := range d.Formatter {
+		args[i] = strings.ReplaceAll(arg, "{width}", strconv.Itoa(width))
+	}
+	return args
+}
+
+func (d DiffConfig) PagerArgs() []string {
+	if len(d.Pager) > 0 {
+		return d.Pager
+	}
+	if pager := strings.Fields(os.Getenv("PAGER")); len(pager) > 0 {
+		return pager
+	}
+	return []string{"less", "-R"}
 }
 
 type OpLogConfig struct {
 	Limit int `toml:"limit"`
 }
 
+type GitConfig struct {
+	// ProtectedBookmarks are glob patterns (e.g. main, release/*) that need an extra confirmation before being pushed
+	ProtectedBookmarks []string `toml:"protected_bookmarks"`
+	// StackBookmarkPattern is used to name missing bookmarks in the stack view.
+	// {change_id}, {commit_id} and {index} (1-based position from trunk) are replaced.
+	StackBookmarkPattern string `toml:"stack_bookmark_pattern"`
+}
+
+func (g GitConfig) IsProtected(bookmark string) bool {
+	for _, pattern := range g.ProtectedBookmarks {
+		if matched, _ := path.Match(pattern, bookmark); matched {
+			return true
+		}
+	}
+	return false
+}
+
 func getConfigFilePath() string {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
@@ -75,15 +187,82 @@ func getDefaultEditor() string {
 
 func Load() *Config {
 	configFile := getConfigFilePath()
-	_, err := os.Stat(configFile)
+	if _, err := os.Stat(configFile); err == nil {
+		_, _ = toml.DecodeFile(configFile, &Current)
+	}
+	LoadPreviewLayout()
+	return Current
+}
+
+// previewLayout is the layout of the preview chosen with the keys. It's kept in the user cache dir
+// so that the config file, which users edit by hand, is never rewritten.
+type previewLayout struct {
+	Position string  `json:"position"`
+	Ratio    float64 `json:"ratio"`
+}
+
+// savedLayout is the layout in the layout file, or the configured layout when there is none
+var savedLayout previewLayout
+
+func getPreviewLayoutPath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "jjui", "preview_layout.json")
+}
+
+// LoadPreviewLayout applies the layout saved at the end of the last session over the configured layout
+func LoadPreviewLayout() {
+	savedLayout = previewLayout{Position: Current.Preview.Position, Ratio: Current.Preview.Ratio}
+	data, err := os.ReadFile(getPreviewLayoutPath())
+	if err != nil {
+		return
+	}
+	var layout previewLayout
+	if err := json.Unmarshal(data, &layout); err != nil || layout.Position == "" {
+		return
+	}
+	Current.Preview.Position = layout.Position
+	Current.Preview.Ratio = layout.Ratio
+	savedLayout = layout
+}
+
+// SavePreviewLayout writes the layout of the preview when it was changed during the session
+func SavePreviewLayout() error {
+	layout := previewLayout{Position: Current.Preview.Position, Ratio: Current.Preview.Ratio}
+	if layout == savedLayout {
+		return nil
+	}
+	layoutFile := getPreviewLayoutPath()
+	if layoutFile == "" {
+		return errors.New("cannot find the cache directory")
+	}
+	data, err := json.Marshal(layout)
 	if err != nil {
-		return Current
+		return err
 	}
-	_, err = toml.DecodeFile(configFile, &Current)
+	if err := os.MkdirAll(filepath.Dir(layoutFile), 0755); err != nil {
+		return err
+	}
+	// written to a temporary file first so that a concurrent session never reads a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(layoutFile), "preview_layout-*.json")
 	if err != nil {
-		return Current
+		return err
 	}
-	return Current
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), layoutFile); err != nil {
+		return err
+	}
+	savedLayout = layout
+	return nil
 }
 
 func Edit() int {
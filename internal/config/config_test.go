@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewConfig_Resize(t *testing.T) {
+	p := PreviewConfig{Ratio: 0.5}
+	p.Resize(1)
+	assert.Equal(t, 0.6, p.Ratio)
+	p.Resize(-2)
+	assert.Equal(t, 0.4, p.Ratio)
+	p.Resize(10)
+	assert.Equal(t, maxPreviewRatio, p.Ratio)
+	p.Resize(-10)
+	assert.Equal(t, minPreviewRatio, p.Ratio)
+}
+
+func TestPreviewConfig_SplitRatio(t *testing.T) {
+	assert.Equal(t, maxPreviewRatio, (&PreviewConfig{Ratio: 1}).SplitRatio())
+	assert.Equal(t, minPreviewRatio, (&PreviewConfig{Ratio: 0}).SplitRatio())
+}
+
+func TestPreviewConfig_TogglePosition(t *testing.T) {
+	p := PreviewConfig{Position: PreviewRight}
+	p.TogglePosition()
+	assert.Equal(t, PreviewBottom, p.Position)
+	p.TogglePosition()
+	assert.Equal(t, PreviewRight, p.Position)
+}
+
+func TestPreviewLayout_SavedOnlyWhenChanged(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	previous := Current.Preview
+	defer func() { Current.Preview = previous }()
+	layoutFile := filepath.Join(cacheDir, "jjui", "preview_layout.json")
+
+	Current.Preview.Position, Current.Preview.Ratio = PreviewRight, 0.5
+	LoadPreviewLayout()
+	assert.NoError(t, SavePreviewLayout())
+	assert.NoFileExists(t, layoutFile)
+
+	Current.Preview.TogglePosition()
+	Current.Preview.Resize(1)
+	assert.NoError(t, SavePreviewLayout())
+	assert.FileExists(t, layoutFile)
+
+	Current.Preview.Position, Current.Preview.Ratio = PreviewRight, 0.5
+	LoadPreviewLayout()
+	assert.Equal(t, PreviewBottom, Current.Preview.Position)
+	assert.Equal(t, 0.6, Current.Preview.Ratio)
+
+	entries, _ := os.ReadDir(filepath.Dir(layoutFile))
+	assert.Len(t, entries, 1, "the temporary file is renamed")
+}
@@ -20,6 +20,8 @@ var DefaultKeyMappings = KeyMappings[keys]{
 	Abandon:          []string{"a"},
 	Edit:             []string{"e"},
 	Diff:             []string{"d"},
+	Compare:          []string{"="},
+	RangeDiff:        []string{"R"},
 	Diffedit:         []string{"E"},
 	Absorb:           []string{"A"},
 	Split:            []string{"s"},
@@ -42,10 +44,13 @@ var DefaultKeyMappings = KeyMappings[keys]{
 		Mode:                  []string{"l"},
 		Close:                 []string{"h"},
 		Split:                 []string{"s"},
+		Squash:                []string{"S"},
 		Restore:               []string{"r"},
 		Diff:                  []string{"d"},
 		ToggleSelect:          []string{"m", " "},
 		RevisionsChangingFile: []string{"*"},
+		Annotate:              []string{"a"},
+		SelectHunks:           []string{"i"},
 	},
 	Preview: previewModeKeys[keys]{
 		Mode:         []string{"p"},
@@ -53,24 +58,70 @@ var DefaultKeyMappings = KeyMappings[keys]{
 		ScrollDown:   []string{"ctrl+n"},
 		HalfPageDown: []string{"ctrl+d"},
 		HalfPageUp:   []string{"ctrl+u"},
+		SideBySide:   []string{"ctrl+t"},
+		Format:       []string{"ctrl+f"},
+		Whitespace:   []string{"ctrl+w"},
+		Context:      []string{"ctrl+x"},
+		Position:     []string{"ctrl+o"},
+		Grow:         []string{"ctrl+right"},
+		Shrink:       []string{"ctrl+left"},
+		Maximize:     []string{"ctrl+e"},
+		Search:       []string{"ctrl+s"},
+		NextMatch:    []string{"alt+n"},
+		PrevMatch:    []string{"alt+p"},
+		ScrollLeft:   []string{"alt+left"},
+		ScrollRight:  []string{"alt+right"},
 	},
 	Bookmark: bookmarkModeKeys[keys]{
-		Mode:    []string{"b"},
-		Set:     []string{"B"},
-		Delete:  []string{"d"},
-		Move:    []string{"m"},
-		Forget:  []string{"f"},
-		Track:   []string{"t"},
-		Untrack: []string{"u"},
+		Mode:     []string{"b"},
+		Set:      []string{"B"},
+		Delete:   []string{"d"},
+		Move:     []string{"m"},
+		Forget:   []string{"f"},
+		Track:    []string{"t"},
+		Untrack:  []string{"u"},
+		Rename:   []string{"r"},
+		Resolve:  []string{"c"},
+		Overview: []string{"o"},
 	},
 	Git: gitModeKeys[keys]{
-		Mode:  []string{"g"},
-		Push:  []string{"p"},
-		Fetch: []string{"f"},
+		Mode:            []string{"g"},
+		Push:            []string{"p"},
+		Fetch:           []string{"f"},
+		Remote:          []string{"r"},
+		Stack:           []string{"s"},
+		CreateBookmarks: []string{"c"},
+	},
+	DiffView: diffViewModeKeys[keys]{
+		NextFile:         []string{"}"},
+		PrevFile:         []string{"{"},
+		NextHunk:         []string{"]"},
+		PrevHunk:         []string{"["},
+		Search:           []string{"/"},
+		NextMatch:        []string{"n"},
+		PrevMatch:        []string{"N"},
+		ToggleSideBySide: []string{"s"},
+		Format:           []string{"f"},
+		Whitespace:       []string{"w"},
+		Context:          []string{"c"},
+		Pager:            []string{"p"},
+	},
+	EvologView: evologViewKeys[keys]{
+		Restore:   []string{"r"},
+		Interdiff: []string{"i"},
+	},
+	Tag: tagModeKeys[keys]{
+		Mode:   []string{"t"},
+		Create: []string{"c"},
+		Delete: []string{"d"},
 	},
 	OpLog: opLogModeKeys[keys]{
-		Mode:    []string{"o"},
-		Restore: []string{"r"},
+		Mode:      []string{"o"},
+		Restore:   []string{"r"},
+		RangeDiff: []string{"R"},
+		Undo:      []string{"u"},
+		Abandon:   []string{"a"},
+		Filter:    []string{"f"},
 	},
 }
 
@@ -85,6 +136,8 @@ func Convert(m KeyMappings[keys]) KeyMappings[key.Binding] {
 		Refresh:          key.NewBinding(key.WithKeys(m.Refresh...), key.WithHelp(join(m.Refresh), "refresh")),
 		Quit:             key.NewBinding(key.WithKeys(m.Quit...), key.WithHelp(join(m.Quit), "quit")),
 		Diff:             key.NewBinding(key.WithKeys(m.Diff...), key.WithHelp(join(m.Diff), "diff")),
+		Compare:          key.NewBinding(key.WithKeys(m.Compare...), key.WithHelp(join(m.Compare), "compare with marked")),
+		RangeDiff:        key.NewBinding(key.WithKeys(m.RangeDiff...), key.WithHelp(join(m.RangeDiff), "range diff of stack")),
 		Describe:         key.NewBinding(key.WithKeys(m.Describe...), key.WithHelp(join(m.Describe), "describe")),
 		Undo:             key.NewBinding(key.WithKeys(m.Undo...), key.WithHelp(join(m.Undo), "undo")),
 		Abandon:          key.NewBinding(key.WithKeys(m.Abandon...), key.WithHelp(join(m.Abandon), "abandon")),
@@ -111,19 +164,25 @@ func Convert(m KeyMappings[keys]) KeyMappings[key.Binding] {
 			Mode:                  key.NewBinding(key.WithKeys(m.Details.Mode...), key.WithHelp(join(m.Details.Mode), "details")),
 			Close:                 key.NewBinding(key.WithKeys(m.Details.Close...), key.WithHelp(join(m.Details.Close), "close")),
 			Split:                 key.NewBinding(key.WithKeys(m.Details.Split...), key.WithHelp(join(m.Details.Split), "details split")),
+			Squash:                key.NewBinding(key.WithKeys(m.Details.Squash...), key.WithHelp(join(m.Details.Squash), "details squash")),
 			Restore:               key.NewBinding(key.WithKeys(m.Details.Restore...), key.WithHelp(join(m.Details.Restore), "details restore")),
 			Diff:                  key.NewBinding(key.WithKeys(m.Details.Diff...), key.WithHelp(join(m.Details.Diff), "details diff")),
 			ToggleSelect:          key.NewBinding(key.WithKeys(m.Details.ToggleSelect...), key.WithHelp(join(m.Details.ToggleSelect), "details toggle select")),
-			RevisionsChangingFile: key.NewBinding(key.WithKeys(m.Details.RevisionsChangingFile...), key.WithHelp(join(m.Details.RevisionsChangingFile), "show revisions changing file")),
+			RevisionsChangingFile: key.NewBinding(key.WithKeys(m.Details.RevisionsChangingFile...), key.WithHelp(join(m.Details.RevisionsChangingFile), "file history")),
+			Annotate:              key.NewBinding(key.WithKeys(m.Details.Annotate...), key.WithHelp(join(m.Details.Annotate), "annotate")),
+			SelectHunks:           key.NewBinding(key.WithKeys(m.Details.SelectHunks...), key.WithHelp(join(m.Details.SelectHunks), "select hunks")),
 		},
 		Bookmark: bookmarkModeKeys[key.Binding]{
-			Mode:    key.NewBinding(key.WithKeys(m.Bookmark.Mode...), key.WithHelp(join(m.Bookmark.Mode), "bookmarks")),
-			Set:     key.NewBinding(key.WithKeys(m.Bookmark.Set...), key.WithHelp(join(m.Bookmark.Set), "set bookmark")),
-			Delete:  key.NewBinding(key.WithKeys(m.Bookmark.Delete...), key.WithHelp(join(m.Bookmark.Delete), "delete")),
-			Move:    key.NewBinding(key.WithKeys(m.Bookmark.Move...), key.WithHelp(join(m.Bookmark.Move), "move")),
-			Forget:  key.NewBinding(key.WithKeys(m.Bookmark.Forget...), key.WithHelp(join(m.Bookmark.Forget), "forget")),
-			Track:   key.NewBinding(key.WithKeys(m.Bookmark.Track...), key.WithHelp(join(m.Bookmark.Track), "track")),
-			Untrack: key.NewBinding(key.WithKeys(m.Bookmark.Untrack...), key.WithHelp(join(m.Bookmark.Untrack), "untrack")),
+			Mode:     key.NewBinding(key.WithKeys(m.Bookmark.Mode...), key.WithHelp(join(m.Bookmark.Mode), "bookmarks")),
+			Set:      key.NewBinding(key.WithKeys(m.Bookmark.Set...), key.WithHelp(join(m.Bookmark.Set), "set bookmark")),
+			Delete:   key.NewBinding(key.WithKeys(m.Bookmark.Delete...), key.WithHelp(join(m.Bookmark.Delete), "delete")),
+			Move:     key.NewBinding(key.WithKeys(m.Bookmark.Move...), key.WithHelp(join(m.Bookmark.Move), "move")),
+			Forget:   key.NewBinding(key.WithKeys(m.Bookmark.Forget...), key.WithHelp(join(m.Bookmark.Forget), "forget")),
+			Track:    key.NewBinding(key.WithKeys(m.Bookmark.Track...), key.WithHelp(join(m.Bookmark.Track), "track")),
+			Untrack:  key.NewBinding(key.WithKeys(m.Bookmark.Untrack...), key.WithHelp(join(m.Bookmark.Untrack), "untrack")),
+			Rename:   key.NewBinding(key.WithKeys(m.Bookmark.Rename...), key.WithHelp(join(m.Bookmark.Rename), "rename")),
+			Resolve:  key.NewBinding(key.WithKeys(m.Bookmark.Resolve...), key.WithHelp(join(m.Bookmark.Resolve), "resolve conflict")),
+			Overview: key.NewBinding(key.WithKeys(m.Bookmark.Overview...), key.WithHelp(join(m.Bookmark.Overview), "overview")),
 		},
 		Preview: previewModeKeys[key.Binding]{
 			Mode:         key.NewBinding(key.WithKeys(m.Preview.Mode...), key.WithHelp(join(m.Preview.Mode), "preview")),
@@ -131,15 +190,58 @@ func Convert(m KeyMappings[keys]) KeyMappings[key.Binding] {
 			ScrollDown:   key.NewBinding(key.WithKeys(m.Preview.ScrollDown...), key.WithHelp(join(m.Preview.ScrollDown), "preview scroll down")),
 			HalfPageDown: key.NewBinding(key.WithKeys(m.Preview.HalfPageDown...), key.WithHelp(join(m.Preview.HalfPageDown), "preview half page down")),
 			HalfPageUp:   key.NewBinding(key.WithKeys(m.Preview.HalfPageUp...), key.WithHelp(join(m.Preview.HalfPageUp), "preview half page up")),
+			SideBySide:   key.NewBinding(key.WithKeys(m.Preview.SideBySide...), key.WithHelp(join(m.Preview.SideBySide), "preview side by side")),
+			Format:       key.NewBinding(key.WithKeys(m.Preview.Format...), key.WithHelp(join(m.Preview.Format), "preview diff format")),
+			Whitespace:   key.NewBinding(key.WithKeys(m.Preview.Whitespace...), key.WithHelp(join(m.Preview.Whitespace), "preview ignore whitespace")),
+			Context:      key.NewBinding(key.WithKeys(m.Preview.Context...), key.WithHelp(join(m.Preview.Context), "preview context lines")),
+			Position:     key.NewBinding(key.WithKeys(m.Preview.Position...), key.WithHelp(join(m.Preview.Position), "preview right/bottom")),
+			Grow:         key.NewBinding(key.WithKeys(m.Preview.Grow...), key.WithHelp(join(m.Preview.Grow), "preview grow")),
+			Shrink:       key.NewBinding(key.WithKeys(m.Preview.Shrink...), key.WithHelp(join(m.Preview.Shrink), "preview shrink")),
+			Maximize:     key.NewBinding(key.WithKeys(m.Preview.Maximize...), key.WithHelp(join(m.Preview.Maximize), "preview maximize")),
+			Search:       key.NewBinding(key.WithKeys(m.Preview.Search...), key.WithHelp(join(m.Preview.Search), "preview search")),
+			NextMatch:    key.NewBinding(key.WithKeys(m.Preview.NextMatch...), key.WithHelp(join(m.Preview.NextMatch), "preview next match")),
+			PrevMatch:    key.NewBinding(key.WithKeys(m.Preview.PrevMatch...), key.WithHelp(join(m.Preview.PrevMatch), "preview previous match")),
+			ScrollLeft:   key.NewBinding(key.WithKeys(m.Preview.ScrollLeft...), key.WithHelp(join(m.Preview.ScrollLeft), "preview scroll left")),
+			ScrollRight:  key.NewBinding(key.WithKeys(m.Preview.ScrollRight...), key.WithHelp(join(m.Preview.ScrollRight), "preview scroll right")),
 		},
 		Git: gitModeKeys[key.Binding]{
-			Mode:  key.NewBinding(key.WithKeys(m.Git.Mode...), key.WithHelp(join(m.Git.Mode), "git")),
-			Push:  key.NewBinding(key.WithKeys(m.Git.Push...), key.WithHelp(join(m.Git.Push), "git push")),
-			Fetch: key.NewBinding(key.WithKeys(m.Git.Fetch...), key.WithHelp(join(m.Git.Fetch), "git fetch")),
+			Mode:            key.NewBinding(key.WithKeys(m.Git.Mode...), key.WithHelp(join(m.Git.Mode), "git")),
+			Push:            key.NewBinding(key.WithKeys(m.Git.Push...), key.WithHelp(join(m.Git.Push), "git push")),
+			Fetch:           key.NewBinding(key.WithKeys(m.Git.Fetch...), key.WithHelp(join(m.Git.Fetch), "git fetch")),
+			Remote:          key.NewBinding(key.WithKeys(m.Git.Remote...), key.WithHelp(join(m.Git.Remote), "git remote")),
+			Stack:           key.NewBinding(key.WithKeys(m.Git.Stack...), key.WithHelp(join(m.Git.Stack), "push stack")),
+			CreateBookmarks: key.NewBinding(key.WithKeys(m.Git.CreateBookmarks...), key.WithHelp(join(m.Git.CreateBookmarks), "create missing bookmarks")),
+		},
+		DiffView: diffViewModeKeys[key.Binding]{
+			NextFile:         key.NewBinding(key.WithKeys(m.DiffView.NextFile...), key.WithHelp(join(m.DiffView.NextFile), "next file")),
+			PrevFile:         key.NewBinding(key.WithKeys(m.DiffView.PrevFile...), key.WithHelp(join(m.DiffView.PrevFile), "previous file")),
+			NextHunk:         key.NewBinding(key.WithKeys(m.DiffView.NextHunk...), key.WithHelp(join(m.DiffView.NextHunk), "next hunk")),
+			PrevHunk:         key.NewBinding(key.WithKeys(m.DiffView.PrevHunk...), key.WithHelp(join(m.DiffView.PrevHunk), "previous hunk")),
+			Search:           key.NewBinding(key.WithKeys(m.DiffView.Search...), key.WithHelp(join(m.DiffView.Search), "search")),
+			NextMatch:        key.NewBinding(key.WithKeys(m.DiffView.NextMatch...), key.WithHelp(join(m.DiffView.NextMatch), "next match")),
+			PrevMatch:        key.NewBinding(key.WithKeys(m.DiffView.PrevMatch...), key.WithHelp(join(m.DiffView.PrevMatch), "previous match")),
+			ToggleSideBySide: key.NewBinding(key.WithKeys(m.DiffView.ToggleSideBySide...), key.WithHelp(join(m.DiffView.ToggleSideBySide), "side by side")),
+			Format:           key.NewBinding(key.WithKeys(m.DiffView.Format...), key.WithHelp(join(m.DiffView.Format), "format")),
+			Whitespace:       key.NewBinding(key.WithKeys(m.DiffView.Whitespace...), key.WithHelp(join(m.DiffView.Whitespace), "ignore whitespace")),
+			Context:          key.NewBinding(key.WithKeys(m.DiffView.Context...), key.WithHelp(join(m.DiffView.Context), "context lines")),
+			Pager:            key.NewBinding(key.WithKeys(m.DiffView.Pager...), key.WithHelp(join(m.DiffView.Pager), "open in pager")),
+		},
+		EvologView: evologViewKeys[key.Binding]{
+			Restore:   key.NewBinding(key.WithKeys(m.EvologView.Restore...), key.WithHelp(join(m.EvologView.Restore), "restore this version")),
+			Interdiff: key.NewBinding(key.WithKeys(m.EvologView.Interdiff...), key.WithHelp(join(m.EvologView.Interdiff), "interdiff")),
+		},
+		Tag: tagModeKeys[key.Binding]{
+			Mode:   key.NewBinding(key.WithKeys(m.Tag.Mode...), key.WithHelp(join(m.Tag.Mode), "tags")),
+			Create: key.NewBinding(key.WithKeys(m.Tag.Create...), key.WithHelp(join(m.Tag.Create), "create tag")),
+			Delete: key.NewBinding(key.WithKeys(m.Tag.Delete...), key.WithHelp(join(m.Tag.Delete), "delete tag")),
 		},
 		OpLog: opLogModeKeys[key.Binding]{
-			Mode:    key.NewBinding(key.WithKeys(m.OpLog.Mode...), key.WithHelp(join(m.OpLog.Mode), "oplog")),
-			Restore: key.NewBinding(key.WithKeys(m.OpLog.Restore...), key.WithHelp(join(m.OpLog.Restore), "restore")),
+			Mode:      key.NewBinding(key.WithKeys(m.OpLog.Mode...), key.WithHelp(join(m.OpLog.Mode), "oplog")),
+			Restore:   key.NewBinding(key.WithKeys(m.OpLog.Restore...), key.WithHelp(join(m.OpLog.Restore), "restore")),
+			RangeDiff: key.NewBinding(key.WithKeys(m.OpLog.RangeDiff...), key.WithHelp(join(m.OpLog.RangeDiff), "range diff of @ since operation")),
+			Undo:      key.NewBinding(key.WithKeys(m.OpLog.Undo...), key.WithHelp(join(m.OpLog.Undo), "undo operation")),
+			Abandon:   key.NewBinding(key.WithKeys(m.OpLog.Abandon...), key.WithHelp(join(m.OpLog.Abandon), "abandon operations")),
+			Filter:    key.NewBinding(key.WithKeys(m.OpLog.Filter...), key.WithHelp(join(m.OpLog.Filter), "filter")),
 		},
 	}
 }
@@ -177,6 +279,8 @@ type KeyMappings[T any] struct {
 	Refresh          T                   `toml:"refresh"`
 	Abandon          T                   `toml:"abandon"`
 	Diff             T                   `toml:"diff"`
+	Compare          T                   `toml:"compare"`
+	RangeDiff        T                   `toml:"range_diff"`
 	Quit             T                   `toml:"quit"`
 	Help             T                   `toml:"help"`
 	Describe         T                   `toml:"describe"`
@@ -195,17 +299,23 @@ type KeyMappings[T any] struct {
 	Preview          previewModeKeys[T]  `toml:"preview"`
 	Bookmark         bookmarkModeKeys[T] `toml:"bookmark"`
 	Git              gitModeKeys[T]      `toml:"git"`
+	DiffView         diffViewModeKeys[T] `toml:"diff_view"`
+	EvologView       evologViewKeys[T]   `toml:"evolog_view"`
+	Tag              tagModeKeys[T]      `toml:"tag"`
 	OpLog            opLogModeKeys[T]    `toml:"oplog"`
 }
 
 type bookmarkModeKeys[T any] struct {
-	Mode    T `toml:"mode"`
-	Set     T `toml:"set"`
-	Delete  T `toml:"delete"`
-	Move    T `toml:"move"`
-	Forget  T `toml:"forget"`
-	Track   T `toml:"track"`
-	Untrack T `toml:"untrack"`
+	Mode     T `toml:"mode"`
+	Set      T `toml:"set"`
+	Delete   T `toml:"delete"`
+	Move     T `toml:"move"`
+	Forget   T `toml:"forget"`
+	Track    T `toml:"track"`
+	Untrack  T `toml:"untrack"`
+	Rename   T `toml:"rename"`
+	Resolve  T `toml:"resolve"`
+	Overview T `toml:"overview"`
 }
 
 type rebaseModeKeys[T any] struct {
@@ -222,16 +332,48 @@ type detailsModeKeys[T any] struct {
 	Mode                  T `toml:"mode"`
 	Close                 T `toml:"close"`
 	Split                 T `toml:"split"`
+	Squash                T `toml:"squash"`
 	Restore               T `toml:"restore"`
 	Diff                  T `toml:"diff"`
 	ToggleSelect          T `toml:"select"`
 	RevisionsChangingFile T `toml:"revisions_changing_file"`
+	Annotate              T `toml:"annotate"`
+	SelectHunks           T `toml:"select_hunks"`
 }
 
 type gitModeKeys[T any] struct {
-	Mode  T `toml:"mode"`
-	Push  T `toml:"push"`
-	Fetch T `toml:"fetch"`
+	Mode            T `toml:"mode"`
+	Push            T `toml:"push"`
+	Fetch           T `toml:"fetch"`
+	Remote          T `toml:"remote"`
+	Stack           T `toml:"stack"`
+	CreateBookmarks T `toml:"create_bookmarks"`
+}
+
+type diffViewModeKeys[T any] struct {
+	NextFile         T `toml:"next_file"`
+	PrevFile         T `toml:"prev_file"`
+	NextHunk         T `toml:"next_hunk"`
+	PrevHunk         T `toml:"prev_hunk"`
+	Search           T `toml:"search"`
+	NextMatch        T `toml:"next_match"`
+	PrevMatch        T `toml:"prev_match"`
+	ToggleSideBySide T `toml:"toggle_side_by_side"`
+	Format           T `toml:"format"`
+	Whitespace       T `toml:"whitespace"`
+	Context          T `toml:"context"`
+	Pager            T `toml:"pager"`
+}
+
+type evologViewKeys[T any] struct {
+	Restore   T `toml:"restore"`
+	Interdiff T `toml:"interdiff"`
+}
+
+type tagModeKeys[T any] struct {
+	Mode   T `toml:"mode"`
+	Create T `toml:"create"`
+	Delete T `toml:"delete"`
 }
 
 type previewModeKeys[T any] struct {
@@ -240,9 +382,26 @@ type previewModeKeys[T any] struct {
 	ScrollDown   T `toml:"scroll_down"`
 	HalfPageDown T `toml:"half_page_down"`
 	HalfPageUp   T `toml:"half_page_up"`
+	SideBySide   T `toml:"side_by_side"`
+	Format       T `toml:"format"`
+	Whitespace   T `toml:"whitespace"`
+	Context      T `toml:"context"`
+	Position     T `toml:"position"`
+	Grow         T `toml:"grow"`
+	Shrink       T `toml:"shrink"`
+	Maximize     T `toml:"maximize"`
+	Search       T `toml:"search"`
+	NextMatch    T `toml:"next_match"`
+	PrevMatch    T `toml:"prev_match"`
+	ScrollLeft   T `toml:"scroll_left"`
+	ScrollRight  T `toml:"scroll_right"`
 }
 
 type opLogModeKeys[T any] struct {
-	Mode    T `toml:"mode"`
-	Restore T `toml:"restore"`
+	Mode      T `toml:"mode"`
+	Restore   T `toml:"restore"`
+	RangeDiff T `toml:"range_diff"`
+	Undo      T `toml:"undo"`
+	Abandon   T `toml:"abandon"`
+	Filter    T `toml:"filter"`
 }
@@ -0,0 +1,29 @@
+package jj
+
+import (
+	"strings"
+)
+
+const annotateTemplate = `commit.change_id().shortest(8) ++ ";" ++ commit.author().name() ++ ";" ++ content`
+
+type AnnotationLine struct {
+	ChangeId string
+	Author   string
+	Content  string
+}
+
+func ParseAnnotateOutput(output string) []AnnotationLine {
+	var result []AnnotationLine
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		parts := strings.SplitN(line, ";", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		result = append(result, AnnotationLine{
+			ChangeId: parts[0],
+			Author:   parts[1],
+			Content:  parts[2],
+		})
+	}
+	return result
+}
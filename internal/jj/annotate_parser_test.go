@@ -0,0 +1,16 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAnnotateOutput(t *testing.T) {
+	output := "kmtqprws;Jane Doe;package main\nkmtqprws;Jane Doe;\nnzsvnmyx;John Doe;var a = \"b;c\"\n"
+	assert.Equal(t, []AnnotationLine{
+		{ChangeId: "kmtqprws", Author: "Jane Doe", Content: "package main"},
+		{ChangeId: "kmtqprws", Author: "Jane Doe", Content: ""},
+		{ChangeId: "nzsvnmyx", Author: "John Doe", Content: "var a = \"b;c\""},
+	}, ParseAnnotateOutput(output))
+}
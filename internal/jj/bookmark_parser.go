@@ -4,7 +4,7 @@ import (
 	"strings"
 )
 
-const moveBookmarkTemplate = `separate(";", if(remote, name ++ "@" ++ remote, name), if(remote, "true", "false"), tracked, conflict, normal_target.contained_in("%s"), normal_target.commit_id().shortest(1)) ++ "\n"`
+const bookmarkRefTemplate = `name ++ ";" ++ if(remote, remote, "") ++ ";" ++ tracked ++ ";" ++ conflict ++ ";" ++ if(normal_target, normal_target.commit_id().shortest(8), "") ++ ";" ++ if(normal_target, normal_target.change_id(), "") ++ "\n"`
 const allBookmarkTemplate = `separate(";", if(remote, name ++ "@" ++ remote, name), if(remote, "true", "false"), tracked, conflict, 'false', normal_target.commit_id().shortest(1)) ++ "\n"`
 
 type Bookmark struct {
@@ -38,3 +38,64 @@ func ParseBookmarkListOutput(output string) []Bookmark {
 	return result
 
 }
+
+// BookmarkRef is a local or a remote bookmark as listed by `jj bookmark list --all-remotes`
+type BookmarkRef struct {
+	Name     string
+	Remote   string
+	Tracked  bool
+	Conflict bool
+	CommitId string
+	ChangeId string
+}
+
+func (b BookmarkRef) IsLocal() bool {
+	return b.Remote == ""
+}
+
+func ParseBookmarkRefs(output string) []BookmarkRef {
+	var result []BookmarkRef
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(line, ";")
+		if len(parts) < 6 {
+			continue
+		}
+		result = append(result, BookmarkRef{
+			Name:     parts[0],
+			Remote:   parts[1],
+			Tracked:  parts[2] == "true",
+			Conflict: parts[3] == "true",
+			CommitId: parts[4],
+			ChangeId: parts[5],
+		})
+	}
+	return result
+}
+
+type ConflictTarget struct {
+	ChangeId    string
+	CommitId    string
+	Description string
+}
+
+// ParseConflictedTargets parses the added targets (lines starting with `+`) of a conflicted local bookmark
+// from the output of `jj bookmark list --conflicted`
+func ParseConflictedTargets(output string) []ConflictTarget {
+	var result []ConflictTarget
+	for _, line := range strings.Split(output, "\n") {
+		// remote bookmarks are listed with an extra indentation under the local one
+		if !strings.HasPrefix(line, "  + ") {
+			continue
+		}
+		fields := strings.SplitN(strings.TrimPrefix(line, "  + "), " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		target := ConflictTarget{ChangeId: fields[0], CommitId: fields[1]}
+		if len(fields) == 3 {
+			target.Description = fields[2]
+		}
+		result = append(result, target)
+	}
+	return result
+}
@@ -0,0 +1,19 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConflictedTargets(t *testing.T) {
+	output := `main (conflicted):
+  - rlvkpnrz 7d4a1b2c old description
+  + qpvuntsm 230dd059 (empty) first side
+  + zsuskuln 9c8e4a2f second side
+  @origin (behind by 1 commits): rlvkpnrz 7d4a1b2c old description`
+	assert.Equal(t, []ConflictTarget{
+		{ChangeId: "qpvuntsm", CommitId: "230dd059", Description: "(empty) first side"},
+		{ChangeId: "zsuskuln", CommitId: "9c8e4a2f", Description: "second side"},
+	}, ParseConflictedTargets(output))
+}
@@ -2,7 +2,9 @@ package jj
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/idursun/jjui/internal/config"
 )
@@ -43,6 +45,51 @@ func Split(revision string, files []string) CommandArgs {
 	return args
 }
 
+// SelectionDiff includes the whole content of the files so that their changes can be selected line by line
+func SelectionDiff(revision string, files []string) CommandArgs {
+	args := []string{"diff", "-r", revision, "--git", "--color", "never", "--context", strconv.Itoa(math.MaxInt32)}
+	args = append(args, files...)
+	return args
+}
+
+// SplitSelection, SquashSelection and RestoreSelection run jjui as the diff editor to apply the file states
+// saved in the selection file. program is the path of the jjui executable.
+func SplitSelection(revision string, files []string, program string, selection string) CommandArgs {
+	args := []string{"split", "-r", revision}
+	args = append(args, selectionToolArgs(program, selection)...)
+	args = append(args, files...)
+	return args
+}
+
+func SquashSelection(revision string, files []string, program string, selection string) CommandArgs {
+	args := []string{"squash", "-r", revision}
+	args = append(args, selectionToolArgs(program, selection)...)
+	args = append(args, files...)
+	return args
+}
+
+func RestoreSelection(revision string, files []string, program string, selection string) CommandArgs {
+	args := []string{"restore", "-c", revision}
+	args = append(args, selectionToolArgs(program, selection)...)
+	args = append(args, files...)
+	return args
+}
+
+func selectionToolArgs(program string, selection string) []string {
+	tool := "merge-tools." + selectionTool
+	return []string{
+		"--tool", selectionTool,
+		"--config", fmt.Sprintf("%s.program=%s", tool, strconv.Quote(program)),
+		"--config", fmt.Sprintf(`%s.edit-args=[%s, %s, "$right"]`, tool, strconv.Quote("--"+ApplySelectionFlag), strconv.Quote(selection)),
+	}
+}
+
+func SquashFiles(revision string, files []string) CommandArgs {
+	args := []string{"squash", "-r", revision}
+	args = append(args, files...)
+	return args
+}
+
 func Describe(revision string) CommandArgs {
 	return []string{"describe", "-r", revision, "--edit"}
 }
@@ -55,20 +102,59 @@ func Abandon(revision ...string) CommandArgs {
 	return args
 }
 
-func Diff(revision string, fileName string) CommandArgs {
+func Diff(revision string, fileName string, extraArgs ...string) CommandArgs {
 	args := []string{"diff", "-r", revision, "--color", "always"}
+	args = append(args, extraArgs...)
+	if fileName != "" {
+		args = append(args, fileName)
+	}
+	return args
+}
+
+// DiffRange compares the contents of two revisions instead of a revision with its parents
+func DiffRange(from string, to string, fileName string, extraArgs ...string) CommandArgs {
+	args := []string{"diff", "--from", from, "--to", to, "--color", "always"}
+	args = append(args, extraArgs...)
 	if fileName != "" {
 		args = append(args, fileName)
 	}
 	return args
 }
 
+// DiffArgs returns the flags for the given diff format and the whitespace and context options of the preview config.
+// The configured diff tool and formatter take precedence over the format.
+func DiffArgs(format string) []string {
+	if config.Current.Diff.Tool != "" {
+		return []string{"--tool", config.Current.Diff.Tool}
+	}
+	var args []string
+	if len(config.Current.Diff.Formatter) > 0 {
+		// formatters read git formatted diffs
+		format = "git"
+	}
+	if format != "" {
+		args = append(args, "--"+format)
+	}
+	if config.Current.Preview.IgnoreWhitespace {
+		args = append(args, "--ignore-all-space")
+	}
+	if config.Current.Preview.ContextLines > 0 {
+		args = append(args, "--context", strconv.Itoa(config.Current.Preview.ContextLines))
+	}
+	return args
+}
+
 func Restore(revision string, files []string) CommandArgs {
 	args := []string{"restore", "-c", revision}
 	args = append(args, files...)
 	return args
 }
 
+// RestoreFrom replaces the contents of a revision with the contents of another, e.g. an older version of the same change
+func RestoreFrom(from string, into string) CommandArgs {
+	return []string{"restore", "--from", from, "--into", into}
+}
+
 func Undo() CommandArgs {
 	return []string{"undo"}
 }
@@ -77,8 +163,20 @@ func Status(revision string) CommandArgs {
 	return []string{"log", "-r", revision, "--summary", "--no-graph", "--color", "never", "--quiet", "--template", ""}
 }
 
-func BookmarkSet(revision string, name string) CommandArgs {
-	return []string{"bookmark", "set", "-r", revision, name}
+func BookmarkSet(revision string, name string, extraFlags ...string) CommandArgs {
+	args := []string{"bookmark", "set", "-r", revision, name}
+	if extraFlags != nil {
+		args = append(args, extraFlags...)
+	}
+	return args
+}
+
+func BookmarkRename(oldName string, newName string) CommandArgs {
+	return []string{"bookmark", "rename", oldName, newName}
+}
+
+func BookmarkCreate(revision string, name string) CommandArgs {
+	return []string{"bookmark", "create", "-r", revision, name}
 }
 
 func BookmarkMove(revision string, bookmark string, extraFlags ...string) CommandArgs {
@@ -113,18 +211,38 @@ func BookmarkList(revset string) CommandArgs {
 	return []string{"bookmark", "list", "-r", revset, "--template", allBookmarkTemplate, "--color", "never"}
 }
 
-func BookmarkListMovable(revision string) CommandArgs {
-	revsetBefore := fmt.Sprintf("::%s", revision)
-	revsetAfter := fmt.Sprintf("%s::", revision)
-	revset := fmt.Sprintf("%s | %s", revsetBefore, revsetAfter)
-	template := fmt.Sprintf(moveBookmarkTemplate, revsetAfter)
-	return []string{"bookmark", "list", "-r", revset, "--template", template, "--color", "never"}
-}
-
 func BookmarkListAll() CommandArgs {
 	return []string{"bookmark", "list", "-a", "--template", allBookmarkTemplate, "--color", "never"}
 }
 
+func BookmarkListConflicted(name string) CommandArgs {
+	return []string{"bookmark", "list", "--conflicted", name, "--color", "never"}
+}
+
+func BookmarkListRefs() CommandArgs {
+	return []string{"bookmark", "list", "--all-remotes", "--template", bookmarkRefTemplate, "--color", "never"}
+}
+
+func LogCommitIds(revset string) CommandArgs {
+	return []string{"log", "-r", revset, "--no-graph", "--template", `commit_id.short() ++ "\n"`, "--color", "never", "--quiet"}
+}
+
+func TagList() CommandArgs {
+	return []string{"tag", "list", "--template", tagTemplate, "--color", "never"}
+}
+
+func TagSet(revision string, name string) CommandArgs {
+	return []string{"tag", "set", "-r", revision, name}
+}
+
+func TagDelete(name string) CommandArgs {
+	return []string{"tag", "delete", name}
+}
+
+func TagHelp() CommandArgs {
+	return []string{"tag", "--help"}
+}
+
 func GitFetch(flags ...string) CommandArgs {
 	args := []string{"git", "fetch"}
 	if flags != nil {
@@ -141,11 +259,73 @@ func GitPush(flags ...string) CommandArgs {
 	return args
 }
 
-func Show(revision string) CommandArgs {
+func GitRemoteList() CommandArgs {
+	return []string{"git", "remote", "list"}
+}
+
+func GitRemoteAdd(name string, url string) CommandArgs {
+	return []string{"git", "remote", "add", name, url}
+}
+
+func GitRemoteRemove(name string) CommandArgs {
+	return []string{"git", "remote", "remove", name}
+}
+
+func GitRemoteRename(oldName string, newName string) CommandArgs {
+	return []string{"git", "remote", "rename", oldName, newName}
+}
+
+func GitRemoteSetUrl(name string, url string) CommandArgs {
+	return []string{"git", "remote", "set-url", name, url}
+}
+
+func StackLog(revision string) CommandArgs {
+	revset := fmt.Sprintf("trunk()..%s", revision)
+	return []string{"log", "-r", revset, "--reversed", "--no-graph", "--template", stackTemplate, "--color", "never", "--quiet"}
+}
+
+// StackAtOp lists the given changes as they were at the operation, changes that didn't exist are left out
+func StackAtOp(operationId string, changeIds []string) CommandArgs {
+	revsets := make([]string, len(changeIds))
+	for i, changeId := range changeIds {
+		revsets[i] = fmt.Sprintf("present(%s)", changeId)
+	}
+	return []string{"--at-op", operationId, "log", "-r", strings.Join(revsets, " | "), "--no-graph", "--template", stackTemplate, "--color", "never", "--quiet"}
+}
+
+// Predecessor lists the current and the previous commits of the revision
+func Predecessor(revision string) CommandArgs {
+	return []string{"evolog", "-r", revision, "--no-graph", "--limit", "2", "--template", predecessorTemplate, "--color", "never", "--quiet"}
+}
+
+// Interdiff shows the differences between the changes of two commits
+func Interdiff(from string, to string) CommandArgs {
+	return []string{"interdiff", "--from", from, "--to", to, "--color", "always", "--git"}
+}
+
+func FileLog(file string, before string) CommandArgs {
+	revset := fmt.Sprintf("files(exact:%q)", file)
+	if before != "" {
+		revset = fmt.Sprintf("::(%s)- & %s", before, revset)
+	}
+	return []string{"log", "-r", revset, "--no-graph", "--template", fileLogTemplate, "--color", "never", "--quiet"}
+}
+
+func FileAnnotate(revision string, file string) CommandArgs {
+	return []string{"file", "annotate", "-r", revision, "--template", annotateTemplate, "--color", "never", file}
+}
+
+// CommitRefs lists the bookmarks and tags of the commits
+func CommitRefs(commitIds []string) CommandArgs {
+	return []string{"log", "-r", strings.Join(commitIds, " | "), "--no-graph", "--template", commitRefsTemplate, "--color", "never", "--quiet"}
+}
+
+func Show(revision string, extraArgs ...string) CommandArgs {
 	args := []string{"show", "-r", revision, "--color", "always"}
 	if config.Current.Preview.ExtraArgs != nil {
 		args = append(args, config.Current.Preview.ExtraArgs...)
 	}
+	args = append(args, extraArgs...)
 	return args
 }
 
@@ -177,6 +357,30 @@ func OpShow(operationId string) CommandArgs {
 	return []string{"op", "show", operationId, "--color", "always"}
 }
 
+// OpLogInfo lists the operations in a format that can be filtered
+func OpLogInfo(limit int) CommandArgs {
+	args := []string{"op", "log", "--no-graph", "--template", opLogTemplate, "--color", "never", "--quiet"}
+	if limit > 0 {
+		args = append(args, "--limit", strconv.Itoa(limit))
+	}
+	return args
+}
+
+// OpDiff shows the changes to the repository between two operations
+func OpDiff(from string, to string) CommandArgs {
+	return []string{"op", "diff", "--from", from, "--to", to, "--color", "always"}
+}
+
+// OpUndo reverses the changes of the operation, keeping the operations after it
+func OpUndo(operationId string) CommandArgs {
+	return []string{"op", "undo", operationId}
+}
+
+// OpAbandon removes the operations in the range from the operation log
+func OpAbandon(operations string) CommandArgs {
+	return []string{"op", "abandon", operations}
+}
+
 func OpRestore(operationId string) CommandArgs {
 	return []string{"op", "restore", operationId}
 }
@@ -11,6 +11,7 @@ type Commit struct {
 	IsWorkingCopy bool
 	Hidden        bool
 	CommitId      string
+	Tags          []string
 }
 
 func (c Commit) IsRoot() bool {
@@ -0,0 +1,20 @@
+package jj
+
+import (
+	"strings"
+)
+
+const commitRefsTemplate = `commit_id ++ ";" ++ bookmarks ++ ";" ++ tags ++ "\n"`
+
+// ParseCommitRefsOutput maps the full commit ids to their bookmarks and tags as they are shown by `jj show`
+func ParseCommitRefsOutput(output string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		commitId, refs, ok := strings.Cut(line, ";")
+		if !ok || commitId == "" {
+			continue
+		}
+		result[commitId] = refs
+	}
+	return result
+}
@@ -0,0 +1,15 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommitRefsOutput(t *testing.T) {
+	output := "1a2b3c4d5e6f;main feature@origin;v1.0\n5e6f7a8b9c0d;;\n"
+	assert.Equal(t, map[string]string{
+		"1a2b3c4d5e6f": "main feature@origin;v1.0",
+		"5e6f7a8b9c0d": ";",
+	}, ParseCommitRefsOutput(output))
+}
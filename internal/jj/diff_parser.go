@@ -0,0 +1,177 @@
+package jj
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+type DiffLineKind int
+
+const (
+	DiffContext DiffLineKind = iota
+	DiffAdded
+	DiffRemoved
+)
+
+type DiffLine struct {
+	Kind DiffLineKind
+	// OldNumber and NewNumber are 1-based line numbers, 0 when the line doesn't exist on that side
+	OldNumber int
+	NewNumber int
+	Content   string
+	// NoNewline is set when the line is the last line of its side and has no newline at the end
+	NoNewline bool
+	// Line is the index of the line in the diff output
+	Line int
+}
+
+type DiffHunk struct {
+	Header   string
+	OldStart int
+	OldCount int
+	NewStart int
+	NewCount int
+	Lines    []DiffLine
+	// Line is the index of the hunk header in the diff output
+	Line int
+}
+
+type DiffFile struct {
+	Name    string
+	OldName string
+	Added   int
+	Removed int
+	Hunks   []DiffHunk
+	// IsNew and IsDeleted are set when the file doesn't exist on the old or the new side
+	IsNew     bool
+	IsDeleted bool
+	// Line is the index of the `diff --git` line in the diff output
+	Line int
+}
+
+// ParseGitDiff parses the output of `jj diff --git` into files and hunks.
+// Colors are ignored, and line indices refer to the lines of the given output.
+func ParseGitDiff(output string) []DiffFile {
+	return parseGitDiff(output, false)
+}
+
+// ParseRawGitDiff parses uncolored output without stripping anything, so that the content of the lines
+// is kept byte for byte, e.g. to rebuild the files from them
+func ParseRawGitDiff(output string) []DiffFile {
+	return parseGitDiff(output, true)
+}
+
+func parseGitDiff(output string, raw bool) []DiffFile {
+	var files []DiffFile
+	var file *DiffFile
+	var hunk *DiffHunk
+	oldLine, newLine, oldRemaining, newRemaining := 0, 0, 0, 0
+
+	flushHunk := func() {
+		if file != nil && hunk != nil {
+			file.Hunks = append(file.Hunks, *hunk)
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if file != nil {
+			files = append(files, *file)
+		}
+		file = nil
+	}
+
+	for i, line := range strings.Split(output, "\n") {
+		if !raw {
+			line = ansi.Strip(line)
+		}
+		if hunk != nil && (oldRemaining > 0 || newRemaining > 0) {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffAdded, NewNumber: newLine, Content: line[1:], Line: i})
+				file.Added++
+				newLine++
+				newRemaining--
+				continue
+			case strings.HasPrefix(line, "-"):
+				hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffRemoved, OldNumber: oldLine, Content: line[1:], Line: i})
+				file.Removed++
+				oldLine++
+				oldRemaining--
+				continue
+			// some tools strip the trailing space of empty context lines
+			case strings.HasPrefix(line, " "), line == "":
+				content := ""
+				if line != "" {
+					content = line[1:]
+				}
+				hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffContext, OldNumber: oldLine, NewNumber: newLine, Content: content, Line: i})
+				oldLine++
+				newLine++
+				oldRemaining--
+				newRemaining--
+				continue
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+			oldName, newName := parseGitDiffNames(strings.TrimPrefix(line, "diff --git "))
+			file = &DiffFile{Name: newName, OldName: oldName, Line: i}
+		case file == nil:
+			continue
+		case strings.HasPrefix(line, `\`):
+			if hunk != nil && len(hunk.Lines) > 0 {
+				hunk.Lines[len(hunk.Lines)-1].NoNewline = true
+			}
+		case strings.HasPrefix(line, "new file mode"):
+			file.IsNew = true
+		case strings.HasPrefix(line, "deleted file mode"):
+			file.IsDeleted = true
+		case strings.HasPrefix(line, "rename from "):
+			file.OldName = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			file.Name = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "@@ "):
+			flushHunk()
+			hunk = &DiffHunk{Header: line, Line: i}
+			parseHunkHeader(line, hunk)
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+			oldRemaining, newRemaining = hunk.OldCount, hunk.NewCount
+		}
+	}
+	flushFile()
+	return files
+}
+
+func parseGitDiffNames(names string) (string, string) {
+	if strings.HasPrefix(names, "a/") {
+		if idx := strings.Index(names, " b/"); idx != -1 {
+			return names[2:idx], names[idx+3:]
+		}
+	}
+	return names, names
+}
+
+func parseHunkHeader(header string, hunk *DiffHunk) {
+	var oldRange, newRange string
+	if _, err := fmt.Sscanf(header, "@@ %s %s @@", &oldRange, &newRange); err != nil {
+		return
+	}
+	hunk.OldStart, hunk.OldCount = parseHunkRange(strings.TrimPrefix(oldRange, "-"))
+	hunk.NewStart, hunk.NewCount = parseHunkRange(strings.TrimPrefix(newRange, "+"))
+}
+
+func parseHunkRange(r string) (int, int) {
+	start, count := 0, 1
+	if before, after, found := strings.Cut(r, ","); found {
+		fmt.Sscanf(before, "%d", &start)
+		fmt.Sscanf(after, "%d", &count)
+	} else {
+		fmt.Sscanf(r, "%d", &start)
+	}
+	return start, count
+}
@@ -0,0 +1,69 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const gitDiff = "diff --git a/main.go b/main.go\n" +
+	"index 1111111..2222222 100644\n" +
+	"--- a/main.go\n" +
+	"+++ b/main.go\n" +
+	"@@ -1,3 +1,3 @@\n" +
+	" package main\n" +
+	"--- old comment\n" +
+	"+// new comment\n" +
+	" func main() {}\n" +
+	"@@ -10,1 +10,2 @@ func other() {\n" +
+	" a\n" +
+	"+b\n" +
+	"diff --git a/old.go b/new.go\n" +
+	"rename from old.go\n" +
+	"rename to new.go\n"
+
+func TestParseGitDiff(t *testing.T) {
+	files := ParseGitDiff(gitDiff)
+	assert.Len(t, files, 2)
+
+	main := files[0]
+	assert.Equal(t, "main.go", main.Name)
+	assert.Equal(t, 0, main.Line)
+	assert.Equal(t, 2, main.Added)
+	assert.Equal(t, 1, main.Removed)
+	assert.Len(t, main.Hunks, 2)
+	assert.Equal(t, 4, main.Hunks[0].Line)
+	assert.Equal(t, []DiffLine{
+		{Kind: DiffContext, OldNumber: 1, NewNumber: 1, Content: "package main", Line: 5},
+		{Kind: DiffRemoved, OldNumber: 2, Content: "-- old comment", Line: 6},
+		{Kind: DiffAdded, NewNumber: 2, Content: "// new comment", Line: 7},
+		{Kind: DiffContext, OldNumber: 3, NewNumber: 3, Content: "func main() {}", Line: 8},
+	}, main.Hunks[0].Lines)
+	assert.Equal(t, 10, main.Hunks[1].NewStart)
+	assert.Equal(t, 2, main.Hunks[1].NewCount)
+
+	renamed := files[1]
+	assert.Equal(t, "new.go", renamed.Name)
+	assert.Equal(t, "old.go", renamed.OldName)
+	assert.Equal(t, 12, renamed.Line)
+	assert.Empty(t, renamed.Hunks)
+}
+
+func TestParseGitDiff_IgnoresColors(t *testing.T) {
+	files := ParseGitDiff("\x1b[1mdiff --git a/a.txt b/a.txt\x1b[0m\n\x1b[36m@@ -1 +1 @@\x1b[0m\n\x1b[31m-a\x1b[0m\n\x1b[32m+b\x1b[0m\n")
+	assert.Len(t, files, 1)
+	assert.Equal(t, "a.txt", files[0].Name)
+	assert.Len(t, files[0].Hunks[0].Lines, 2)
+	assert.Equal(t, "b", files[0].Hunks[0].Lines[1].Content)
+}
+
+func TestParseGitDiff_EmptyContextLine(t *testing.T) {
+	files := ParseGitDiff("diff --git a/a.txt b/a.txt\n@@ -1,3 +1,3 @@\n a\n\n-b\n+c\ndiff --git a/b.txt b/b.txt\n")
+	assert.Len(t, files, 2)
+	assert.Equal(t, []DiffLine{
+		{Kind: DiffContext, OldNumber: 1, NewNumber: 1, Content: "a", Line: 2},
+		{Kind: DiffContext, OldNumber: 2, NewNumber: 2, Content: "", Line: 3},
+		{Kind: DiffRemoved, OldNumber: 3, Content: "b", Line: 4},
+		{Kind: DiffAdded, NewNumber: 3, Content: "c", Line: 5},
+	}, files[0].Hunks[0].Lines)
+}
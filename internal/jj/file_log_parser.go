@@ -0,0 +1,66 @@
+package jj
+
+import (
+	"path"
+	"strings"
+)
+
+const fileLogTemplate = `change_id.shortest(8) ++ ";" ++ commit_id.shortest(8) ++ ";" ++ author.name() ++ ";" ++ committer.timestamp().ago() ++ ";" ++ description.first_line() ++ "\n"`
+
+type FileLogEntry struct {
+	ChangeId    string
+	CommitId    string
+	Author      string
+	Timestamp   string
+	Description string
+	// Path is the name of the file at this revision, which differs from the requested file before a rename
+	Path string
+}
+
+func ParseFileLogOutput(output string, file string) []FileLogEntry {
+	var result []FileLogEntry
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, ";", 5)
+		if len(parts) < 5 {
+			continue
+		}
+		result = append(result, FileLogEntry{
+			ChangeId:    parts[0],
+			CommitId:    parts[1],
+			Author:      parts[2],
+			Timestamp:   parts[3],
+			Description: parts[4],
+			Path:        file,
+		})
+	}
+	return result
+}
+
+// RenameSource finds the original name of the file in the `--summary` output of a revision
+// that renamed it (e.g. `R internal/{old.go => new.go}`)
+func RenameSource(summary string, file string) (string, bool) {
+	for _, line := range strings.Split(summary, "\n") {
+		if !strings.HasPrefix(line, "R ") {
+			continue
+		}
+		from, to, ok := parseRename(line[2:])
+		if ok && to == file {
+			return from, true
+		}
+	}
+	return "", false
+}
+
+func parseRename(name string) (string, string, bool) {
+	start := strings.Index(name, "{")
+	end := strings.Index(name, "}")
+	if start == -1 || end < start {
+		return "", "", false
+	}
+	parts := strings.Split(name[start+1:end], " => ")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	prefix, suffix := name[:start], name[end+1:]
+	return path.Clean(prefix + parts[0] + suffix), path.Clean(prefix + parts[1] + suffix), true
+}
@@ -0,0 +1,30 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFileLogOutput(t *testing.T) {
+	output := "kmtqprws;1a2b3c4d;Jane Doe;2 days ago;fix: a; b\nnzsvnmyx;5e6f7a8b;John Doe;1 year ago;initial\n"
+	assert.Equal(t, []FileLogEntry{
+		{ChangeId: "kmtqprws", CommitId: "1a2b3c4d", Author: "Jane Doe", Timestamp: "2 days ago", Description: "fix: a; b", Path: "main.go"},
+		{ChangeId: "nzsvnmyx", CommitId: "5e6f7a8b", Author: "John Doe", Timestamp: "1 year ago", Description: "initial", Path: "main.go"},
+	}, ParseFileLogOutput(output, "main.go"))
+}
+
+func TestRenameSource(t *testing.T) {
+	summary := "M README.md\nR internal/ui/{old.go => new.go}\nR {docs => doc}/guide.md\n"
+
+	from, ok := RenameSource(summary, "internal/ui/new.go")
+	assert.True(t, ok)
+	assert.Equal(t, "internal/ui/old.go", from)
+
+	from, ok = RenameSource(summary, "doc/guide.md")
+	assert.True(t, ok)
+	assert.Equal(t, "docs/guide.md", from)
+
+	_, ok = RenameSource(summary, "README.md")
+	assert.False(t, ok)
+}
@@ -0,0 +1,37 @@
+package jj
+
+import (
+	"strings"
+	"time"
+)
+
+const opLogTemplate = `id.short() ++ ";" ++ user ++ ";" ++ time.start().format("%Y-%m-%dT%H:%M:%S%:z") ++ ";" ++ description.first_line() ++ "\n"`
+
+// OperationInfo is what the operation log can be filtered by
+type OperationInfo struct {
+	OperationId string
+	User        string
+	Time        time.Time
+	Description string
+}
+
+func ParseOpLogInfoOutput(output string) []OperationInfo {
+	var result []OperationInfo
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, ";", 4)
+		if len(parts) < 4 {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, parts[2])
+		if err != nil {
+			continue
+		}
+		result = append(result, OperationInfo{
+			OperationId: parts[0],
+			User:        parts[1],
+			Time:        start,
+			Description: parts[3],
+		})
+	}
+	return result
+}
@@ -0,0 +1,20 @@
+package jj
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOpLogInfoOutput(t *testing.T) {
+	output := "8f2f3c1a9b0d;alice@host;2024-05-02T10:15:00+02:00;rebase commit 1234abcd\n" +
+		"1a2b3c4d5e6f;bob@host;2024-05-01T08:00:00+00:00;snapshot working copy; with a semicolon\n" +
+		"broken line\n"
+	infos := ParseOpLogInfoOutput(output)
+	assert.Len(t, infos, 2)
+	assert.Equal(t, "8f2f3c1a9b0d", infos[0].OperationId)
+	assert.Equal(t, "alice@host", infos[0].User)
+	assert.True(t, infos[0].Time.Equal(time.Date(2024, 5, 2, 8, 15, 0, 0, time.UTC)))
+	assert.Equal(t, "snapshot working copy; with a semicolon", infos[1].Description)
+}
@@ -0,0 +1,96 @@
+package jj
+
+import (
+	"strings"
+)
+
+type PushChangeKind int
+
+const (
+	PushAdd PushChangeKind = iota
+	PushMoveForward
+	PushMoveSideways
+	PushMoveBackward
+	PushDelete
+)
+
+type PushChange struct {
+	Remote   string
+	Bookmark string
+	Kind     PushChangeKind
+	From     string
+	To       string
+}
+
+// IsForce reports whether the change rewrites the remote bookmark to a commit that is not a descendant of its current target
+func (p PushChange) IsForce() bool {
+	return p.Kind == PushMoveSideways || p.Kind == PushMoveBackward
+}
+
+// ParsePushDryRunOutput parses the output of `jj git push --dry-run` into a list of bookmark changes
+func ParsePushDryRunOutput(output string) []PushChange {
+	var result []PushChange
+	remote := ""
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "Changes to push to ") {
+			remote = strings.TrimSuffix(strings.TrimPrefix(line, "Changes to push to "), ":")
+			continue
+		}
+		if !strings.HasPrefix(line, "  ") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		change := PushChange{Remote: remote}
+		switch {
+		case fields[0] == "Add":
+			change.Kind = PushAdd
+		case fields[0] == "Delete":
+			change.Kind = PushDelete
+		case fields[0] == "Force":
+			change.Kind = PushMoveSideways
+		case fields[0] == "Move" && fields[1] == "forward":
+			change.Kind = PushMoveForward
+		case fields[0] == "Move" && fields[1] == "sideways":
+			change.Kind = PushMoveSideways
+		case fields[0] == "Move" && fields[1] == "backward":
+			change.Kind = PushMoveBackward
+		default:
+			continue
+		}
+		for i := 0; i < len(fields)-1; i++ {
+			switch fields[i] {
+			case "bookmark", "branch":
+				if change.Bookmark == "" {
+					change.Bookmark = fields[i+1]
+				}
+			case "from":
+				change.From = fields[i+1]
+			case "to":
+				change.To = fields[i+1]
+			}
+		}
+		if change.Bookmark == "" {
+			continue
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+// ParseCreatedBookmarks returns the names of the bookmarks generated by `jj git push --change`
+func ParseCreatedBookmarks(output string) []string {
+	var result []string
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "Creating bookmark ") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 3 {
+			result = append(result, fields[2])
+		}
+	}
+	return result
+}
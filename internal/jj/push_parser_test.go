@@ -0,0 +1,38 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePushDryRunOutput(t *testing.T) {
+	output := `Changes to push to origin:
+  Add bookmark feature to 4d1e5b2a9c3f
+  Move forward bookmark main from 0f8a1c3b0cdb to 7c4d0e2a9f3b
+  Move sideways bookmark topic from 1111 to 2222
+  Move backward bookmark old from 3333 to 4444
+  Delete bookmark stale from 5555
+Dry-run requested, not pushing.`
+	changes := ParsePushDryRunOutput(output)
+	assert.Equal(t, []PushChange{
+		{Remote: "origin", Bookmark: "feature", Kind: PushAdd, To: "4d1e5b2a9c3f"},
+		{Remote: "origin", Bookmark: "main", Kind: PushMoveForward, From: "0f8a1c3b0cdb", To: "7c4d0e2a9f3b"},
+		{Remote: "origin", Bookmark: "topic", Kind: PushMoveSideways, From: "1111", To: "2222"},
+		{Remote: "origin", Bookmark: "old", Kind: PushMoveBackward, From: "3333", To: "4444"},
+		{Remote: "origin", Bookmark: "stale", Kind: PushDelete, From: "5555"},
+	}, changes)
+}
+
+func TestParsePushDryRunOutput_NothingChanged(t *testing.T) {
+	assert.Empty(t, ParsePushDryRunOutput("Nothing changed."))
+}
+
+func TestParseCreatedBookmarks(t *testing.T) {
+	output := `Creating bookmark push-nzsvnmyxppwk for revision nzsvnmyxppwk
+Creating bookmark push-kmtqprwsoxyz for revision kmtqprwsoxyz
+Changes to push to origin:
+  Add bookmark push-nzsvnmyxppwk to 0c9ce3ee5e12
+  Add bookmark push-kmtqprwsoxyz to 5a3e1b2c4d6f`
+	assert.Equal(t, []string{"push-nzsvnmyxppwk", "push-kmtqprwsoxyz"}, ParseCreatedBookmarks(output))
+}
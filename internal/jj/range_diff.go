@@ -0,0 +1,60 @@
+package jj
+
+import (
+	"strings"
+)
+
+const predecessorTemplate = `commit_id.shortest(8) ++ ";" ++ commit_id ++ "\n"`
+
+// RangeDiffPair is a change of a stack with its current and previous commits.
+// Old is empty when the change has no previous version or when it's divergent.
+type RangeDiffPair struct {
+	ChangeId    string
+	Description string
+	// Old and New are the shortest commit ids to display, OldCommitId and NewCommitId are the full ones
+	Old         string
+	New         string
+	OldCommitId string
+	NewCommitId string
+	// Divergent is set when the change has more than one commit in either stack, so it can't be paired
+	Divergent bool
+}
+
+func (p RangeDiffPair) Unchanged() bool {
+	return p.OldCommitId != "" && p.OldCommitId == p.NewCommitId
+}
+
+// PairWithPrevious pairs the entries of the stack with the entries of the same changes in the previous stack by their full change ids
+func PairWithPrevious(current []StackEntry, previous []StackEntry) []RangeDiffPair {
+	counts := make(map[string]int)
+	for _, entry := range current {
+		counts[entry.FullChangeId]++
+	}
+	commits := make(map[string][]StackEntry)
+	for _, p := range previous {
+		commits[p.FullChangeId] = append(commits[p.FullChangeId], p)
+	}
+	pairs := make([]RangeDiffPair, len(current))
+	for i, entry := range current {
+		pairs[i] = RangeDiffPair{ChangeId: entry.ChangeId, Description: entry.Description, New: entry.CommitId, NewCommitId: entry.FullCommitId}
+		old := commits[entry.FullChangeId]
+		switch {
+		case counts[entry.FullChangeId] > 1 || len(old) > 1:
+			pairs[i].Divergent = true
+		case len(old) == 1:
+			pairs[i].Old, pairs[i].OldCommitId = old[0].CommitId, old[0].FullCommitId
+		}
+	}
+	return pairs
+}
+
+// ParsePredecessor returns the shortest and the full ids of the previous commit from the output of the evolog of a change,
+// which lists the current commit first
+func ParsePredecessor(output string) (string, string) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return "", ""
+	}
+	shortId, commitId, _ := strings.Cut(strings.TrimSpace(lines[1]), ";")
+	return shortId, commitId
+}
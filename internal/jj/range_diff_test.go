@@ -0,0 +1,55 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPairWithPrevious(t *testing.T) {
+	current := []StackEntry{
+		{ChangeId: "kmtqprws", FullChangeId: "kmtqprwsxyzz", CommitId: "1a2b3c4d", FullCommitId: "1a2b3c4d5e6f", Description: "first"},
+		{ChangeId: "nzsvnmyx", FullChangeId: "nzsvnmyxkkpq", CommitId: "5e6f7a8b", FullCommitId: "5e6f7a8b9c0d", Description: "second"},
+		{ChangeId: "zz", FullChangeId: "zzzzzzzzzzzz", CommitId: "9a9a9a9a", FullCommitId: "9a9a9a9a9a9a", Description: "new"},
+	}
+	previous := []StackEntry{
+		// the shortest commit id was longer at the operation
+		{ChangeId: "kmt", FullChangeId: "kmtqprwsxyzz", CommitId: "1a2b3c4d5", FullCommitId: "1a2b3c4d5e6f"},
+		{ChangeId: "nzsvnmyxk", FullChangeId: "nzsvnmyxkkpq", CommitId: "0f0f0f0f", FullCommitId: "0f0f0f0f0f0f"},
+		// the short id is a prefix of the new change's, but it's a different change
+		{ChangeId: "zzzz", FullChangeId: "zzzzyyyyyyyy", CommitId: "1b1b1b1b", FullCommitId: "1b1b1b1b1b1b"},
+	}
+	pairs := PairWithPrevious(current, previous)
+	assert.True(t, pairs[0].Unchanged())
+	assert.Equal(t, "1a2b3c4d5", pairs[0].Old)
+	assert.Equal(t, "0f0f0f0f", pairs[1].Old)
+	assert.Equal(t, "0f0f0f0f0f0f", pairs[1].OldCommitId)
+	assert.False(t, pairs[1].Unchanged())
+	assert.Equal(t, "", pairs[2].Old)
+}
+
+func TestPairWithPrevious_Divergent(t *testing.T) {
+	current := []StackEntry{
+		{ChangeId: "kmtqprws/0", FullChangeId: "kmtqprwsxyzz", CommitId: "1a2b3c4d"},
+		{ChangeId: "kmtqprws/1", FullChangeId: "kmtqprwsxyzz", CommitId: "2b3c4d5e"},
+		{ChangeId: "nzsvnmyx", FullChangeId: "nzsvnmyxkkpq", CommitId: "5e6f7a8b"},
+	}
+	previous := []StackEntry{
+		{ChangeId: "kmtqprws", FullChangeId: "kmtqprwsxyzz", CommitId: "0a0a0a0a"},
+		{ChangeId: "nzsvnmyx", FullChangeId: "nzsvnmyxkkpq", CommitId: "0f0f0f0f"},
+		{ChangeId: "nzsvnmyx", FullChangeId: "nzsvnmyxkkpq", CommitId: "0e0e0e0e"},
+	}
+	for _, pair := range PairWithPrevious(current, previous) {
+		assert.True(t, pair.Divergent)
+		assert.Equal(t, "", pair.Old)
+	}
+}
+
+func TestParsePredecessor(t *testing.T) {
+	shortId, commitId := ParsePredecessor("5e6f7a8b;5e6f7a8b9c0d\n0f0f0f0f;0f0f0f0f0f0f\n")
+	assert.Equal(t, "0f0f0f0f", shortId)
+	assert.Equal(t, "0f0f0f0f0f0f", commitId)
+	shortId, commitId = ParsePredecessor("5e6f7a8b;5e6f7a8b9c0d\n")
+	assert.Equal(t, "", shortId)
+	assert.Equal(t, "", commitId)
+}
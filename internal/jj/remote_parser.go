@@ -0,0 +1,27 @@
+package jj
+
+import (
+	"strings"
+)
+
+type Remote struct {
+	Name string
+	Url  string
+}
+
+func ParseRemoteListOutput(output string) []Remote {
+	lines := strings.Split(output, "\n")
+	var result []Remote
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, url, _ := strings.Cut(line, " ")
+		result = append(result, Remote{
+			Name: name,
+			Url:  strings.TrimSpace(url),
+		})
+	}
+	return result
+}
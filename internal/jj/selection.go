@@ -0,0 +1,111 @@
+package jj
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// selectionTool is the name of the merge tool jjui registers to apply the selected changes
+const selectionTool = "jjui-selection"
+
+// ApplySelectionFlag is the flag jjui is started with when jj runs it as the diff editor
+const ApplySelectionFlag = "apply-selection"
+
+// FileState is the content a file should have after applying the selected changes
+type FileState struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Deleted bool   `json:"deleted"`
+}
+
+// CanSelectChanges reports whether the changes of the file can be selected line by line.
+// It requires the whole file in a single hunk (i.e. unlimited context) and doesn't support renames.
+// Files with escape sequences or invalid UTF-8 are not supported either, as they can't be shown
+// as they are and the selection is saved as JSON.
+func CanSelectChanges(file DiffFile) bool {
+	if len(file.Hunks) != 1 || file.OldName != file.Name {
+		return false
+	}
+	for _, line := range file.Hunks[0].Lines {
+		if !utf8.ValidString(line.Content) || ansi.Strip(line.Content) != line.Content {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectedFileState builds the content of the file with only the changes for which apply returns true.
+// The file has to be parsed with ParseRawGitDiff so that the lines that are not changed are kept as they are.
+// apply is called with the index of each added or removed line in the hunk.
+func SelectedFileState(file DiffFile, apply func(line int) bool) FileState {
+	var lines []string
+	noNewline, applied, total := false, 0, 0
+	for i, line := range file.Hunks[0].Lines {
+		keep := line.Kind == DiffContext
+		if line.Kind != DiffContext {
+			total++
+			if apply(i) {
+				applied++
+				keep = line.Kind == DiffAdded
+			} else {
+				keep = line.Kind == DiffRemoved
+			}
+		}
+		if keep {
+			lines = append(lines, line.Content)
+			noNewline = line.NoNewline
+		}
+	}
+	content := strings.Join(lines, "\n")
+	if len(lines) > 0 && !noNewline {
+		content += "\n"
+	}
+	deleted := (file.IsNew && applied == 0) || (file.IsDeleted && applied == total)
+	return FileState{Path: file.Name, Content: content, Deleted: deleted}
+}
+
+// WriteSelection saves the file states to a temporary file that is passed to jjui when jj runs it as the diff editor
+func WriteSelection(states []FileState) (string, error) {
+	f, err := os.CreateTemp("", "jjui-selection-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(states); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// ApplySelection writes the file states saved by WriteSelection into the directory jj asks the diff editor to edit
+func ApplySelection(selection string, dir string) error {
+	data, err := os.ReadFile(selection)
+	if err != nil {
+		return err
+	}
+	var states []FileState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return err
+	}
+	for _, state := range states {
+		target := filepath.Join(dir, filepath.FromSlash(state.Path))
+		if state.Deleted {
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, []byte(state.Content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
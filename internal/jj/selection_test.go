@@ -0,0 +1,86 @@
+package jj
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const selectionDiff = "diff --git a/main.go b/main.go\n" +
+	"--- a/main.go\n" +
+	"+++ b/main.go\n" +
+	"@@ -1,4 +1,4 @@\n" +
+	" package main\n" +
+	"-one\n" +
+	"+ONE\n" +
+	" middle\n" +
+	"-two\n" +
+	"\\ No newline at end of file\n" +
+	"+TWO\n" +
+	"\\ No newline at end of file\n" +
+	"diff --git a/new.txt b/new.txt\n" +
+	"new file mode 100644\n" +
+	"--- /dev/null\n" +
+	"+++ b/new.txt\n" +
+	"@@ -0,0 +1,1 @@\n" +
+	"+hello\n"
+
+func TestSelectedFileState(t *testing.T) {
+	files := ParseRawGitDiff(selectionDiff)
+	assert.True(t, CanSelectChanges(files[0]))
+
+	all := SelectedFileState(files[0], func(int) bool { return true })
+	assert.Equal(t, "package main\nONE\nmiddle\nTWO", all.Content)
+
+	none := SelectedFileState(files[0], func(int) bool { return false })
+	assert.Equal(t, "package main\none\nmiddle\ntwo", none.Content)
+
+	// only the first change
+	first := SelectedFileState(files[0], func(line int) bool { return line <= 2 })
+	assert.Equal(t, "package main\nONE\nmiddle\ntwo", first.Content)
+	assert.False(t, first.Deleted)
+}
+
+func TestSelectedFileState_NewFile(t *testing.T) {
+	files := ParseRawGitDiff(selectionDiff)
+	assert.True(t, files[1].IsNew)
+	assert.True(t, SelectedFileState(files[1], func(int) bool { return false }).Deleted)
+
+	state := SelectedFileState(files[1], func(int) bool { return true })
+	assert.False(t, state.Deleted)
+	assert.Equal(t, "hello\n", state.Content)
+}
+
+func TestSelectedFileState_KeepsRawContent(t *testing.T) {
+	diff := "diff --git a/raw.txt b/raw.txt\n" +
+		"--- a/raw.txt\n" +
+		"+++ b/raw.txt\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		" \x1b[31mred\x1b[0m\n" +
+		" caf\xe9\n" +
+		"-old\n" +
+		"+new\n"
+	files := ParseRawGitDiff(diff)
+	state := SelectedFileState(files[0], func(int) bool { return true })
+	assert.Equal(t, "\x1b[31mred\x1b[0m\ncaf\xe9\nnew\n", state.Content)
+	assert.False(t, CanSelectChanges(files[0]))
+}
+
+func TestApplySelection(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "removed.txt"), []byte("x"), 0644))
+	selection, err := WriteSelection([]FileState{
+		{Path: "sub/file.txt", Content: "content\n"},
+		{Path: "removed.txt", Deleted: true},
+	})
+	assert.NoError(t, err)
+	defer os.Remove(selection)
+
+	assert.NoError(t, ApplySelection(selection, dir))
+	content, err := os.ReadFile(filepath.Join(dir, "sub", "file.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "content\n", string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "removed.txt"))
+}
@@ -0,0 +1,40 @@
+package jj
+
+import (
+	"strings"
+)
+
+const stackTemplate = `change_id.shortest(8) ++ ";" ++ change_id ++ ";" ++ commit_id.shortest(8) ++ ";" ++ commit_id ++ ";" ++ local_bookmarks.map(|b| b.name()).join(",") ++ ";" ++ description.first_line() ++ "\n"`
+
+type StackEntry struct {
+	ChangeId string
+	// FullChangeId identifies the change across operations, where the shortest unique prefixes may differ
+	FullChangeId string
+	CommitId     string
+	// FullCommitId is compared across operations for the same reason
+	FullCommitId string
+	Bookmarks    []string
+	Description  string
+}
+
+func ParseStackOutput(output string) []StackEntry {
+	var result []StackEntry
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, ";", 6)
+		if len(parts) < 6 {
+			continue
+		}
+		entry := StackEntry{
+			ChangeId:     parts[0],
+			FullChangeId: parts[1],
+			CommitId:     parts[2],
+			FullCommitId: parts[3],
+			Description:  parts[5],
+		}
+		if parts[4] != "" {
+			entry.Bookmarks = strings.Split(parts[4], ",")
+		}
+		result = append(result, entry)
+	}
+	return result
+}
@@ -0,0 +1,16 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStackOutput(t *testing.T) {
+	output := "kmtqprws;kmtqprwsxyzz;5a3e1b2c;5a3e1b2cfull;feature-a;first; with semicolon\nnzsvnmyx;nzsvnmyxkkpq;0c9ce3ee;0c9ce3eefull;;second\nqpvuntsm;qpvuntsmwlqt;e0d1c2b3;e0d1c2b3full;b,c;"
+	assert.Equal(t, []StackEntry{
+		{ChangeId: "kmtqprws", FullChangeId: "kmtqprwsxyzz", CommitId: "5a3e1b2c", FullCommitId: "5a3e1b2cfull", Bookmarks: []string{"feature-a"}, Description: "first; with semicolon"},
+		{ChangeId: "nzsvnmyx", FullChangeId: "nzsvnmyxkkpq", CommitId: "0c9ce3ee", FullCommitId: "0c9ce3eefull", Description: "second"},
+		{ChangeId: "qpvuntsm", FullChangeId: "qpvuntsmwlqt", CommitId: "e0d1c2b3", FullCommitId: "e0d1c2b3full", Bookmarks: []string{"b", "c"}},
+	}, ParseStackOutput(output))
+}
@@ -0,0 +1,65 @@
+package jj
+
+import (
+	"strings"
+)
+
+const tagTemplate = `name ++ ";" ++ if(normal_target, normal_target.commit_id().shortest(8), "") ++ ";" ++ if(normal_target, normal_target.change_id(), "") ++ ";" ++ if(normal_target, normal_target.description().first_line(), "") ++ "\n"`
+
+type Tag struct {
+	Name        string
+	CommitId    string
+	ChangeId    string
+	Description string
+}
+
+func ParseTagListOutput(output string) []Tag {
+	var result []Tag
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, ";", 4)
+		if len(parts) < 4 || parts[0] == "" {
+			continue
+		}
+		result = append(result, Tag{
+			Name:        parts[0],
+			CommitId:    parts[1],
+			ChangeId:    parts[2],
+			Description: parts[3],
+		})
+	}
+	return result
+}
+
+// SupportsTagEditing reports whether `jj tag --help` lists the set and delete subcommands.
+// Older versions of jj can only list tags.
+func SupportsTagEditing(helpOutput string) bool {
+	hasSet, hasDelete := false, false
+	for _, line := range strings.Split(helpOutput, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "set":
+			hasSet = true
+		case "delete":
+			hasDelete = true
+		}
+	}
+	return hasSet && hasDelete
+}
+
+// AttachTags sets the tags of the commits whose (possibly shortened) commit id prefixes the tag target
+func AttachTags(commits []*Commit, tags []Tag) {
+	for _, commit := range commits {
+		commit.Tags = nil
+		if commit.CommitId == "" {
+			continue
+		}
+		for _, tag := range tags {
+			if tag.CommitId != "" && (strings.HasPrefix(tag.CommitId, commit.CommitId) || strings.HasPrefix(commit.CommitId, tag.CommitId)) {
+				commit.Tags = append(commit.Tags, tag.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package jj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTagListOutput(t *testing.T) {
+	output := "v1.0;1a2b3c4d;kmtqprwsoxyz;release 1.0\nv1.1;5e6f7a8b;nzsvnmyxppwk;fix; and more\n"
+	assert.Equal(t, []Tag{
+		{Name: "v1.0", CommitId: "1a2b3c4d", ChangeId: "kmtqprwsoxyz", Description: "release 1.0"},
+		{Name: "v1.1", CommitId: "5e6f7a8b", ChangeId: "nzsvnmyxppwk", Description: "fix; and more"},
+	}, ParseTagListOutput(output))
+}
+
+func TestSupportsTagEditing(t *testing.T) {
+	assert.False(t, SupportsTagEditing("Manage tags\n\nUsage: jj tag <COMMAND>\n\nCommands:\n  list  List tags\n  help  Print this message\n"))
+	assert.True(t, SupportsTagEditing("Usage: jj tag <COMMAND>\n\nCommands:\n  delete  Delete existing tags\n  list    List tags\n  set     Create or update tags\n"))
+}
+
+func TestAttachTags(t *testing.T) {
+	commits := []*Commit{{CommitId: "1a2b"}, {CommitId: "ffff"}}
+	AttachTags(commits, []Tag{{Name: "v1.0", CommitId: "1a2b3c4d"}, {Name: "v1.0.1", CommitId: "1a2b3c4d"}})
+	assert.Equal(t, []string{"v1.0", "v1.0.1"}, commits[0].Tags)
+	assert.Empty(t, commits[1].Tags)
+}
@@ -0,0 +1,161 @@
+package annotate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/operations/details"
+)
+
+const authorWidth = 16
+
+type updateAnnotationsMsg struct {
+	lines []jj.AnnotationLine
+}
+
+type Model struct {
+	context  context.AppContext
+	keymap   config.KeyMappings[key.Binding]
+	revision string
+	file     string
+	lines    []jj.AnnotationLine
+	err      string
+	cursor   int
+	top      int
+	width    int
+	height   int
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.load
+}
+
+func (m *Model) load() tea.Msg {
+	output, err := m.context.RunCommandImmediate(jj.FileAnnotate(m.revision, m.file))
+	if err != nil {
+		return common.CommandCompletedMsg{Output: string(output), Err: err}
+	}
+	return updateAnnotationsMsg{lines: jj.ParseAnnotateOutput(string(output))}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case updateAnnotationsMsg:
+		m.lines = msg.lines
+		m.cursor, m.top = 0, 0
+	case common.CommandCompletedMsg:
+		if msg.Err != nil {
+			m.err = strings.TrimSpace(msg.Output)
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keymap.Cancel):
+			return m, common.Close
+		case key.Matches(msg, m.keymap.Up):
+			m.moveCursor(-1)
+		case key.Matches(msg, m.keymap.Down):
+			m.moveCursor(1)
+		case key.Matches(msg, m.keymap.Preview.HalfPageUp):
+			m.moveCursor(-m.pageSize() / 2)
+		case key.Matches(msg, m.keymap.Preview.HalfPageDown):
+			m.moveCursor(m.pageSize() / 2)
+		case key.Matches(msg, m.keymap.Apply):
+			if m.cursor >= len(m.lines) {
+				return m, nil
+			}
+			// close first so that the revisions view receives the message
+			return m, tea.Sequence(common.Close, details.Show(m.lines[m.cursor].ChangeId))
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) pageSize() int {
+	return max(m.height-2, 1)
+}
+
+func (m *Model) moveCursor(delta int) {
+	m.cursor = max(0, min(len(m.lines)-1, m.cursor+delta))
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+m.pageSize() {
+		m.top = m.cursor - m.pageSize() + 1
+	}
+}
+
+func (m *Model) View() string {
+	title := lipgloss.JoinHorizontal(0,
+		common.DefaultPalette.Normal.Bold(true).Render(m.file),
+		" ",
+		common.DefaultPalette.Dimmed.Render("at "),
+		common.DefaultPalette.ChangeId.Render(m.revision),
+	)
+	var content string
+	if m.err != "" {
+		content = common.DefaultPalette.StatusError.Render(m.err)
+	} else if m.lines == nil {
+		content = "loading"
+	} else {
+		numberWidth := len(fmt.Sprint(len(m.lines)))
+		end := min(len(m.lines), m.top+m.pageSize())
+		var rendered []string
+		for i := m.top; i < end; i++ {
+			line := m.lines[i]
+			changeId, author := "", ""
+			// like git blame, repeated origins are only shown on the first line of a block
+			if i == m.top || m.lines[i-1].ChangeId != line.ChangeId {
+				changeId, author = line.ChangeId, line.Author
+			}
+			if runes := []rune(author); len(runes) > authorWidth {
+				author = string(runes[:authorWidth-1]) + "…"
+			}
+			row := lipgloss.JoinHorizontal(0,
+				common.DefaultPalette.ChangeId.Render(fmt.Sprintf("%-8s", changeId)),
+				" ",
+				common.DefaultPalette.Dimmed.Render(fmt.Sprintf("%-*s", authorWidth, author)),
+				" ",
+				common.DefaultPalette.Dimmed.Render(fmt.Sprintf("%*d", numberWidth, i+1)),
+				" ",
+				strings.ReplaceAll(line.Content, "\t", "    "),
+			)
+			style := lipgloss.NewStyle().MaxWidth(m.width)
+			if i == m.cursor {
+				style = style.Bold(true).Background(common.IntenseBlack)
+			}
+			rendered = append(rendered, style.Render(row))
+		}
+		content = lipgloss.JoinVertical(0, rendered...)
+	}
+	help := lipgloss.JoinHorizontal(0,
+		renderKey(key.NewBinding(key.WithKeys(m.keymap.Apply.Keys()...), key.WithHelp(m.keymap.Apply.Help().Key, "show change"))),
+		renderKey(m.keymap.Cancel),
+	)
+	content = lipgloss.Place(m.width, max(m.height-2, 0), 0, 0, content)
+	return lipgloss.JoinVertical(0, title, content, help)
+}
+
+func renderKey(k key.Binding) string {
+	return lipgloss.JoinHorizontal(0, common.DefaultPalette.ChangeId.Render(k.Help().Key, ""), common.DefaultPalette.Dimmed.Render(k.Help().Desc, ""))
+}
+
+func New(context context.AppContext, revision string, file string, width int, height int) *Model {
+	return &Model{
+		context:  context,
+		keymap:   context.KeyMap(),
+		revision: revision,
+		file:     file,
+		width:    width,
+		height:   height,
+	}
+}
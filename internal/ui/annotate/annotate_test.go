@@ -0,0 +1,38 @@
+package annotate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const annotateOutput = "kmtqprws;Jane Doe;package main\nkmtqprws;Jane Doe;\nnzsvnmyx;John Doe;func main() {}\n"
+
+func TestAnnotate_JumpsToChange(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.FileAnnotate("current", "main.go")).SetOutput([]byte(annotateOutput))
+	defer c.Verify()
+
+	tm := teatest.NewTestModel(t, test.NewShell(New(c, "current", "main.go", 80, 20)))
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("nzsvnmyx")) && bytes.Contains(bts, []byte("John Doe"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func TestAnnotate_ShowsOriginOncePerBlock(t *testing.T) {
+	c := test.NewTestContext(t)
+	model := New(c, "current", "main.go", 80, 20)
+	model.Update(updateAnnotationsMsg{lines: jj.ParseAnnotateOutput(annotateOutput)})
+	assert.Equal(t, 1, strings.Count(model.View(), "kmtqprws"))
+}
@@ -4,29 +4,39 @@ import (
 	"fmt"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/operations/bookmark"
 	"slices"
 	"strings"
+	"unicode"
 )
 
 type updateItemsMsg struct {
 	items []list.Item
+	names []string
 }
 
 type Model struct {
-	context context.AppContext
-	current *jj.Commit
-	filter  string
-	list    list.Model
-	items   []list.Item
-	keymap  config.KeyMappings[key.Binding]
-	width   int
-	height  int
+	context  context.AppContext
+	current  *jj.Commit
+	filter   string
+	list     list.Model
+	items    []list.Item
+	overview *overviewModel
+	resolve  *resolveModel
+	names    map[string]bool
+	renaming string
+	input    textinput.Model
+	invalid  string
+	keymap   config.KeyMappings[key.Binding]
+	width    int
+	height   int
 }
 
 func (m *Model) Width() int {
@@ -54,7 +64,9 @@ type commandType int
 // defines the order of actions in the list
 const (
 	moveCommand commandType = iota
+	resolveCommand
 	deleteCommand
+	renameCommand
 	trackCommand
 	untrackCommand
 	forgetCommand
@@ -62,10 +74,13 @@ const (
 
 type item struct {
 	name     string
+	bookmark string
 	priority commandType
 	// used to show bookmarks of the selected revision at the top
 	weight int
 	args   []string
+	// describes the items that prompt for more input before running a command
+	desc string
 }
 
 func (i item) FilterValue() string {
@@ -77,12 +92,14 @@ func (i item) Title() string {
 }
 
 func (i item) Description() string {
-	desc := strings.Join(i.args, " ")
-	return desc
+	if i.desc != "" {
+		return i.desc
+	}
+	return strings.Join(i.args, " ")
 }
 
 func (m *Model) Init() tea.Cmd {
-	return tea.Batch(m.loadAll, m.loadMovables)
+	return m.loadAll
 }
 
 func (m *Model) filtered(filter string) (tea.Model, tea.Cmd) {
@@ -100,34 +117,6 @@ func (m *Model) filtered(filter string) (tea.Model, tea.Cmd) {
 	return m, m.list.SetItems(filtered)
 }
 
-func (m *Model) loadMovables() tea.Msg {
-	output, _ := m.context.RunCommandImmediate(jj.BookmarkListMovable(m.current.GetChangeId()))
-	var bookmarkItems []list.Item
-	bookmarks := jj.ParseBookmarkListOutput(string(output))
-	for _, b := range bookmarks {
-		if b.Remote || (!b.Conflict && b.CommitIdShort == m.current.CommitId) {
-			continue
-		}
-
-		name := fmt.Sprintf("move '%s' to %s", b.Name, m.current.GetChangeId())
-		if b.Conflict {
-			name = fmt.Sprintf("move conflicted '%s' to %s", b.Name, m.current.GetChangeId())
-		}
-		var extraFlags []string
-		if b.Backwards {
-			name = fmt.Sprintf("move '%s' backwards to %s", b.Name, m.current.GetChangeId())
-			extraFlags = append(extraFlags, "--allow-backwards")
-		}
-
-		bookmarkItems = append(bookmarkItems, item{
-			name:     name,
-			priority: moveCommand,
-			args:     jj.BookmarkMove(m.current.GetChangeId(), b.Name, extraFlags...),
-		})
-	}
-	return updateItemsMsg{items: bookmarkItems}
-}
-
 func (m *Model) loadAll() tea.Msg {
 	if output, err := m.context.RunCommandImmediate(jj.BookmarkListAll()); err != nil {
 		return nil
@@ -135,18 +124,47 @@ func (m *Model) loadAll() tea.Msg {
 		bookmarks := jj.ParseBookmarkListOutput(string(output))
 
 		items := make([]list.Item, 0)
+		var names []string
 		for _, b := range bookmarks {
 			weight := 0
 			if m.current.CommitId == b.CommitIdShort {
 				weight = 1
 			}
 			if !b.Remote {
+				names = append(names, b.Name)
+				name := fmt.Sprintf("move '%s'", b.Name)
+				if b.Conflict {
+					name = fmt.Sprintf("move conflicted '%s'", b.Name)
+				}
+				items = append(items, item{
+					name:     name,
+					bookmark: b.Name,
+					priority: moveCommand,
+					weight:   weight,
+					desc:     "move the cursor to the destination",
+				})
 				items = append(items, item{
 					name:     fmt.Sprintf("delete '%s'", b.Name),
 					priority: deleteCommand,
 					weight:   weight,
 					args:     jj.BookmarkDelete(b.Name),
 				})
+				items = append(items, item{
+					name:     fmt.Sprintf("rename '%s'", b.Name),
+					bookmark: b.Name,
+					priority: renameCommand,
+					weight:   weight,
+					desc:     "prompt for the new name",
+				})
+				if b.Conflict {
+					items = append(items, item{
+						name:     fmt.Sprintf("resolve conflicted '%s'", b.Name),
+						bookmark: b.Name,
+						priority: resolveCommand,
+						weight:   weight,
+						desc:     "choose one of the conflicting targets",
+					})
+				}
 			}
 
 			items = append(items, item{
@@ -174,17 +192,45 @@ func (m *Model) loadAll() tea.Msg {
 				})
 			}
 		}
-		return updateItemsMsg{items: items}
+		return updateItemsMsg{items: items, names: names}
 	}
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && m.renaming != "" {
+		return m.updateRename(msg)
+	}
+	if m.resolve != nil {
+		switch msg.(type) {
+		case closeResolveMsg:
+			m.resolve = nil
+			return m, nil
+		case tea.KeyMsg, updateTargetsMsg:
+			var cmd tea.Cmd
+			m.resolve, cmd = m.resolve.Update(msg)
+			return m, cmd
+		}
+	}
+	if m.overview != nil {
+		switch msg.(type) {
+		case closeOverviewMsg:
+			m.overview = nil
+			return m, nil
+		case tea.KeyMsg, updateOverviewMsg, updateCountsMsg:
+			var cmd tea.Cmd
+			m.overview, cmd = m.overview.Update(msg)
+			return m, cmd
+		}
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if m.list.SettingFilter() {
 			break
 		}
 		switch {
+		case key.Matches(msg, m.keymap.Bookmark.Overview):
+			m.overview = newOverviewModel(m.context)
+			return m, m.overview.load
 		case key.Matches(msg, m.keymap.Cancel):
 			if m.filter != "" || m.list.IsFiltered() {
 				m.list.ResetFilter()
@@ -196,6 +242,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 			action := m.list.SelectedItem().(item)
+			switch action.priority {
+			case moveCommand:
+				return m, tea.Batch(common.Close, bookmark.StartMove(action.bookmark))
+			case renameCommand:
+				return m, m.startRename(action.bookmark)
+			case resolveCommand:
+				m.resolve = newResolveModel(m.context, action.bookmark)
+				return m, m.resolve.load
+			}
 			return m, m.context.RunCommand(action.args, common.Refresh, common.Close)
 		case key.Matches(msg, m.keymap.Bookmark.Move):
 			return m.filtered("move")
@@ -207,8 +262,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.filtered("track")
 		case key.Matches(msg, m.keymap.Bookmark.Untrack):
 			return m.filtered("untrack")
+		case key.Matches(msg, m.keymap.Bookmark.Rename):
+			return m.filtered("rename")
+		case key.Matches(msg, m.keymap.Bookmark.Resolve):
+			return m.filtered("resolve")
 		}
 	case updateItemsMsg:
+		for _, name := range msg.names {
+			m.names[name] = true
+		}
 		m.items = append(m.items, msg.items...)
 		slices.SortFunc(m.items, func(a, b list.Item) int {
 			ia := a.(item)
@@ -234,6 +296,57 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m *Model) startRename(bookmark string) tea.Cmd {
+	m.renaming = bookmark
+	m.input.SetValue(bookmark)
+	m.input.CursorEnd()
+	m.invalid = ""
+	return m.input.Focus()
+}
+
+func (m *Model) updateRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keymap.Cancel):
+		m.renaming = ""
+		m.input.Blur()
+		return m, nil
+	case key.Matches(msg, m.keymap.Apply):
+		newName := strings.TrimSpace(m.input.Value())
+		if m.invalid = m.validateName(newName); m.invalid != "" {
+			return m, nil
+		}
+		return m, m.context.RunCommand(jj.BookmarkRename(m.renaming, newName), common.Refresh, common.Close)
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.invalid = m.validateName(strings.TrimSpace(m.input.Value()))
+	return m, cmd
+}
+
+// validateName checks the new name against the existing bookmarks and the rules of git reference names
+func (m *Model) validateName(name string) string {
+	switch {
+	case name == "":
+		return "name cannot be empty"
+	case name == m.renaming:
+		return "name is unchanged"
+	case m.names[name]:
+		return fmt.Sprintf("'%s' already exists", name)
+	case strings.IndexFunc(name, unicode.IsSpace) != -1,
+		strings.ContainsAny(name, "~^:?*[\\"),
+		strings.Contains(name, ".."),
+		strings.Contains(name, "@{"),
+		strings.Contains(name, "//"),
+		strings.HasPrefix(name, "-"),
+		strings.HasPrefix(name, "/"),
+		strings.HasSuffix(name, "/"),
+		strings.HasSuffix(name, "."),
+		strings.HasSuffix(name, ".lock"):
+		return fmt.Sprintf("'%s' is not a valid bookmark name", name)
+	}
+	return ""
+}
+
 var filterStyle = common.DefaultPalette.ChangeId.PaddingLeft(2)
 var filterValueStyle = common.DefaultPalette.Normal.Bold(true)
 
@@ -245,6 +358,25 @@ func (m *Model) View() string {
 	}
 	listView := m.list.View()
 	helpView := m.helpView()
+	if m.renaming != "" {
+		validation := common.DefaultPalette.StatusSuccess.Render("  ✓")
+		if m.invalid != "" {
+			validation = common.DefaultPalette.StatusError.Render("  ✗ " + m.invalid)
+		}
+		listView = lipgloss.JoinVertical(0, "", filterValueStyle.PaddingLeft(2).Render(fmt.Sprintf("rename '%s'", m.renaming)), "", "  "+m.input.View(), validation)
+		helpView = " " + lipgloss.JoinHorizontal(0, renderKey(m.keymap.Apply), renderKey(m.keymap.Cancel))
+	}
+	if m.resolve != nil {
+		filterView = lipgloss.JoinHorizontal(0, filterStyle.Render("Resolve conflicted "), filterValueStyle.Render(m.resolve.bookmark))
+		listView = lipgloss.JoinVertical(0, "", m.resolve.View(m.Width()-4))
+		helpView = m.resolve.helpView()
+	}
+	if m.overview != nil {
+		title = m.list.Styles.Title.Render("Bookmarks")
+		filterView = filterStyle.Render("Ahead/behind tracked remotes")
+		listView = lipgloss.JoinVertical(0, "", m.overview.View(m.Width()-4, m.Height()-8))
+		helpView = m.overview.helpView()
+	}
 	content := lipgloss.JoinVertical(0, title, "", filterView, listView, "", helpView)
 	content = lipgloss.Place(m.Width(), m.Height(), 0, 0, content)
 	return lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Render(content)
@@ -267,6 +399,9 @@ func (m *Model) helpView() string {
 		renderKey(m.keymap.Bookmark.Forget),
 		renderKey(m.keymap.Bookmark.Track),
 		renderKey(m.keymap.Bookmark.Untrack),
+		renderKey(m.keymap.Bookmark.Rename),
+		renderKey(m.keymap.Bookmark.Resolve),
+		renderKey(m.keymap.Bookmark.Overview),
 	}
 	if m.list.IsFiltered() {
 		bindings = append(bindings, renderKey(m.keymap.Cancel))
@@ -289,11 +424,18 @@ func NewModel(c context.AppContext, current *jj.Commit, width int, height int) *
 	l.SetShowHelp(false)
 	l.DisableQuitKeybindings()
 
+	input := textinput.New()
+	input.Prompt = "new name: "
+	input.PromptStyle = common.DefaultPalette.ChangeId
+	input.CharLimit = 120
+
 	m := &Model{
 		context: c,
 		keymap:  c.KeyMap(),
 		list:    l,
 		current: current,
+		names:   make(map[string]bool),
+		input:   input,
 	}
 	m.SetWidth(width)
 	m.SetHeight(height)
@@ -0,0 +1,72 @@
+package bookmarks
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const allBookmarks = "main;false;false;true;false;1111\nfeature;false;false;false;false;2222\n"
+
+func TestRename(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.BookmarkListAll()).SetOutput([]byte(allBookmarks))
+	c.Expect(jj.BookmarkRename("feature", "feature-2"))
+	defer c.Verify()
+
+	tm := teatest.NewTestModel(t, test.NewShell(NewModel(c, &jj.Commit{ChangeId: "current"}, 80, 30)))
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("rename 'feature'"))
+	})
+	tm.Type("r")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlU})
+	tm.Type("main")
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("'main' already exists"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlU})
+	tm.Type("feature-2")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func TestResolveConflicted(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.BookmarkListAll()).SetOutput([]byte(allBookmarks))
+	c.Expect(jj.BookmarkListConflicted("main")).SetOutput([]byte("main (conflicted):\n  + qpvuntsm 230dd059 first\n  + zsuskuln 9c8e4a2f second\n"))
+	c.Expect(jj.BookmarkSet("9c8e4a2f", "main", "--allow-backwards"))
+	defer c.Verify()
+
+	tm := teatest.NewTestModel(t, test.NewShell(NewModel(c, &jj.Commit{ChangeId: "current"}, 80, 30)))
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("resolve conflicted 'main'"))
+	})
+	tm.Type("c")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("zsuskuln"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func TestItemsLoadedWhileRenaming(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.BookmarkListAll()).SetOutput([]byte(allBookmarks))
+	defer c.Verify()
+
+	m := NewModel(c, &jj.Commit{ChangeId: "current"}, 80, 30)
+	m.startRename("feature")
+	m.Update(m.loadAll())
+	assert.NotEmpty(t, m.items)
+	assert.Equal(t, "feature", m.input.Value())
+}
@@ -0,0 +1,221 @@
+package bookmarks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+)
+
+type tracking struct {
+	remote   string
+	conflict bool
+	ahead    int
+	behind   int
+	counted  bool
+}
+
+type overviewRow struct {
+	local    jj.BookmarkRef
+	remotes  []tracking
+	conflict bool
+}
+
+type updateOverviewMsg struct {
+	rows []overviewRow
+}
+
+type updateCountsMsg struct {
+	row    int
+	counts []tracking
+}
+
+type closeOverviewMsg struct{}
+
+// overviewModel is a read-only list of local bookmarks showing how far they are ahead/behind their tracked remotes
+type overviewModel struct {
+	context context.AppContext
+	keymap  config.KeyMappings[key.Binding]
+	rows    []overviewRow
+	cursor  int
+}
+
+func newOverviewModel(c context.AppContext) *overviewModel {
+	return &overviewModel{
+		context: c,
+		keymap:  c.KeyMap(),
+	}
+}
+
+func (o *overviewModel) load() tea.Msg {
+	output, err := o.context.RunCommandImmediate(jj.BookmarkListRefs())
+	if err != nil {
+		return common.CommandCompletedMsg{Output: string(output), Err: err}
+	}
+	refs := jj.ParseBookmarkRefs(string(output))
+	var rows []overviewRow
+	index := make(map[string]int)
+	for _, ref := range refs {
+		if ref.IsLocal() {
+			index[ref.Name] = len(rows)
+			rows = append(rows, overviewRow{local: ref, conflict: ref.Conflict})
+		}
+	}
+	for _, ref := range refs {
+		// the git remote is jj's view of the colocated repository, not a real remote
+		if ref.IsLocal() || !ref.Tracked || ref.Remote == "git" {
+			continue
+		}
+		if i, ok := index[ref.Name]; ok {
+			rows[i].remotes = append(rows[i].remotes, tracking{remote: ref.Remote, conflict: ref.Conflict})
+		}
+	}
+	return updateOverviewMsg{rows: rows}
+}
+
+func (o *overviewModel) countCmds() tea.Cmd {
+	var cmds []tea.Cmd
+	for i, row := range o.rows {
+		if len(row.remotes) == 0 {
+			continue
+		}
+		cmds = append(cmds, o.count(i, row))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (o *overviewModel) count(index int, row overviewRow) tea.Cmd {
+	return func() tea.Msg {
+		local := fmt.Sprintf("bookmarks(exact:%q)", row.local.Name)
+		var counts []tracking
+		for _, t := range row.remotes {
+			remote := fmt.Sprintf("remote_bookmarks(exact:%q, exact:%q)", row.local.Name, t.remote)
+			t.ahead = o.countRevisions(fmt.Sprintf("%s..%s", remote, local))
+			t.behind = o.countRevisions(fmt.Sprintf("%s..%s", local, remote))
+			t.counted = true
+			counts = append(counts, t)
+		}
+		return updateCountsMsg{row: index, counts: counts}
+	}
+}
+
+func (o *overviewModel) countRevisions(revset string) int {
+	output, err := o.context.RunCommandImmediate(jj.LogCommitIds(revset))
+	if err != nil {
+		return -1
+	}
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed == "" {
+		return 0
+	}
+	return strings.Count(trimmed, "\n") + 1
+}
+
+func (o *overviewModel) Update(msg tea.Msg) (*overviewModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case updateOverviewMsg:
+		o.rows = msg.rows
+		o.cursor = 0
+		return o, o.countCmds()
+	case updateCountsMsg:
+		if msg.row < len(o.rows) {
+			o.rows[msg.row].remotes = msg.counts
+		}
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, o.keymap.Cancel):
+			return o, func() tea.Msg { return closeOverviewMsg{} }
+		case key.Matches(msg, o.keymap.Up):
+			if o.cursor > 0 {
+				o.cursor--
+			}
+		case key.Matches(msg, o.keymap.Down):
+			if o.cursor < len(o.rows)-1 {
+				o.cursor++
+			}
+		case key.Matches(msg, o.keymap.Apply):
+			if o.cursor >= len(o.rows) || o.rows[o.cursor].local.ChangeId == "" {
+				return o, nil
+			}
+			return o, tea.Batch(common.Close, common.RefreshAndSelect(o.rows[o.cursor].local.ChangeId))
+		}
+	}
+	return o, nil
+}
+
+func (o *overviewModel) View(width int, height int) string {
+	if o.rows == nil {
+		return "  loading"
+	}
+	if len(o.rows) == 0 {
+		return common.DefaultPalette.Dimmed.Render("  no local bookmarks")
+	}
+
+	nameWidth := 0
+	for _, row := range o.rows {
+		nameWidth = max(nameWidth, lipgloss.Width(row.local.Name))
+	}
+
+	start := max(0, o.cursor-height+1)
+	end := min(len(o.rows), start+height)
+	var lines []string
+	for i := start; i < end; i++ {
+		row := o.rows[i]
+		name := fmt.Sprintf("%-*s", nameWidth, row.local.Name)
+		parts := []string{"  ", common.DefaultPalette.Normal.Render(name), " ", common.DefaultPalette.CommitId.Render(fmt.Sprintf("%-8s", row.local.CommitId))}
+		if row.conflict {
+			parts = append(parts, " ", common.DefaultPalette.StatusError.Render("conflict"))
+		}
+		if len(row.remotes) == 0 {
+			parts = append(parts, " ", common.DefaultPalette.Dimmed.Render("not tracked"))
+		}
+		for _, t := range row.remotes {
+			parts = append(parts, " ", renderTracking(t))
+		}
+		line := lipgloss.JoinHorizontal(0, parts...)
+		if i == o.cursor {
+			line = lipgloss.NewStyle().Bold(true).Background(common.IntenseBlack).Render(line)
+		}
+		lines = append(lines, lipgloss.NewStyle().MaxWidth(width).Render(line))
+	}
+	return lipgloss.JoinVertical(0, lines...)
+}
+
+func renderTracking(t tracking) string {
+	remote := common.DefaultPalette.ChangeId.Render("@" + t.remote)
+	if t.conflict {
+		return remote + common.DefaultPalette.StatusError.Render(" conflict")
+	}
+	if !t.counted {
+		return remote + common.DefaultPalette.Dimmed.Render(" …")
+	}
+	if t.ahead < 0 || t.behind < 0 {
+		return remote + common.DefaultPalette.Dimmed.Render(" ?")
+	}
+	if t.ahead == 0 && t.behind == 0 {
+		return remote + common.DefaultPalette.Dimmed.Render(" in sync")
+	}
+	counts := ""
+	if t.ahead > 0 {
+		counts += common.DefaultPalette.Added.Render(fmt.Sprintf(" ↑%d", t.ahead))
+	}
+	if t.behind > 0 {
+		counts += common.DefaultPalette.Deleted.Render(fmt.Sprintf(" ↓%d", t.behind))
+	}
+	return remote + counts
+}
+
+func (o *overviewModel) helpView() string {
+	return " " + lipgloss.JoinHorizontal(0,
+		renderKey(o.keymap.Up),
+		renderKey(o.keymap.Down),
+		renderKey(key.NewBinding(key.WithKeys(o.keymap.Apply.Keys()...), key.WithHelp(o.keymap.Apply.Help().Key, "jump to target"))),
+		renderKey(o.keymap.Cancel),
+	)
+}
@@ -0,0 +1,30 @@
+package bookmarks
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/test"
+)
+
+func TestOverview_ShowsAheadBehindCounts(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.BookmarkListAll())
+	c.Expect(jj.BookmarkListRefs()).SetOutput([]byte("main;;false;false;1111;kmtqprws\nmain;origin;true;false;2222;nzsvnmyx\n"))
+	c.Expect(jj.LogCommitIds(`remote_bookmarks(exact:"main", exact:"origin")..bookmarks(exact:"main")`)).SetOutput([]byte("1111\n3333"))
+	c.Expect(jj.LogCommitIds(`bookmarks(exact:"main")..remote_bookmarks(exact:"main", exact:"origin")`))
+	defer c.Verify()
+
+	model := NewModel(c, &jj.Commit{ChangeId: "current"}, 80, 30)
+	tm := teatest.NewTestModel(t, test.NewShell(model))
+	tm.Type("o")
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("↑2"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
@@ -0,0 +1,106 @@
+package bookmarks
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+)
+
+type updateTargetsMsg struct {
+	targets []jj.ConflictTarget
+}
+
+type closeResolveMsg struct{}
+
+// resolveModel lists the candidate targets of a conflicted bookmark and sets the bookmark to the chosen one
+type resolveModel struct {
+	context  context.AppContext
+	keymap   config.KeyMappings[key.Binding]
+	bookmark string
+	targets  []jj.ConflictTarget
+	cursor   int
+}
+
+func newResolveModel(c context.AppContext, bookmark string) *resolveModel {
+	return &resolveModel{
+		context:  c,
+		keymap:   c.KeyMap(),
+		bookmark: bookmark,
+	}
+}
+
+func (r *resolveModel) load() tea.Msg {
+	output, err := r.context.RunCommandImmediate(jj.BookmarkListConflicted(r.bookmark))
+	if err != nil {
+		return common.CommandCompletedMsg{Output: string(output), Err: err}
+	}
+	return updateTargetsMsg{targets: jj.ParseConflictedTargets(string(output))}
+}
+
+func (r *resolveModel) Update(msg tea.Msg) (*resolveModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case updateTargetsMsg:
+		r.targets = msg.targets
+		r.cursor = 0
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, r.keymap.Cancel):
+			return r, func() tea.Msg { return closeResolveMsg{} }
+		case key.Matches(msg, r.keymap.Up):
+			if r.cursor > 0 {
+				r.cursor--
+			}
+		case key.Matches(msg, r.keymap.Down):
+			if r.cursor < len(r.targets)-1 {
+				r.cursor++
+			}
+		case key.Matches(msg, r.keymap.Apply):
+			if r.cursor >= len(r.targets) {
+				return r, nil
+			}
+			target := r.targets[r.cursor]
+			return r, r.context.RunCommand(jj.BookmarkSet(target.CommitId, r.bookmark, "--allow-backwards"), common.Refresh, common.Close)
+		}
+	}
+	return r, nil
+}
+
+func (r *resolveModel) View(width int) string {
+	if r.targets == nil {
+		return "  loading"
+	}
+	if len(r.targets) == 0 {
+		return common.DefaultPalette.Dimmed.Render("  no candidate targets found")
+	}
+	var lines []string
+	for i, target := range r.targets {
+		line := lipgloss.JoinHorizontal(0,
+			"  ",
+			common.DefaultPalette.ChangeId.Render(target.ChangeId),
+			" ",
+			common.DefaultPalette.CommitId.Render(target.CommitId),
+			" ",
+			strings.TrimSpace(target.Description),
+		)
+		if i == r.cursor {
+			line = lipgloss.NewStyle().Bold(true).Background(common.IntenseBlack).Render(line)
+		}
+		lines = append(lines, lipgloss.NewStyle().MaxWidth(width).Render(line))
+	}
+	return lipgloss.JoinVertical(0, lines...)
+}
+
+func (r *resolveModel) helpView() string {
+	return " " + lipgloss.JoinHorizontal(0,
+		renderKey(r.keymap.Up),
+		renderKey(r.keymap.Down),
+		renderKey(key.NewBinding(key.WithKeys(r.keymap.Apply.Keys()...), key.WithHelp(r.keymap.Apply.Help().Key, "set bookmark here"))),
+		renderKey(r.keymap.Cancel),
+	)
+}
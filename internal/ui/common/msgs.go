@@ -10,8 +10,21 @@ type (
 	ToggleHelpMsg struct{}
 	RefreshMsg    struct {
 		SelectedRevision string
+		// Tags reloads the tags as well, which are otherwise loaded only once
+		Tags bool
+	}
+	ShowDiffMsg string
+	// ShowRevisionDiffMsg opens the diff viewer for the revision, which can re-run the diff with other formats.
+	// When From is set, the revision is compared with it instead of its parents.
+	ShowRevisionDiffMsg struct {
+		From     string
+		Revision string
+		File     string
+	}
+	ShowAnnotateMsg struct {
+		Revision string
+		File     string
 	}
-	ShowDiffMsg              string
 	UpdateRevisionsFailedMsg struct {
 		Output string
 		Err    error
@@ -26,7 +39,15 @@ type (
 		Err    error
 	}
 	SelectionChangedMsg struct{}
-	QuickSearchMsg      string
+	// RevisionsLoadedMsg lists the commit ids of the revisions after they are (re)loaded
+	RevisionsLoadedMsg struct {
+		CommitIds []string
+	}
+	// PrefetchMsg lists the commits that are likely to be selected next
+	PrefetchMsg struct {
+		CommitIds []string
+	}
+	QuickSearchMsg string
 )
 
 type State int
@@ -51,10 +72,38 @@ func RefreshAndSelect(selectedRevision string) tea.Cmd {
 	}
 }
 
+func ShowRevisionDiff(revision string, file string) tea.Cmd {
+	return func() tea.Msg {
+		return ShowRevisionDiffMsg{Revision: revision, File: file}
+	}
+}
+
+func ShowCompareDiff(from string, to string) tea.Cmd {
+	return func() tea.Msg {
+		return ShowRevisionDiffMsg{From: from, Revision: to}
+	}
+}
+
+func RevisionsLoaded(commitIds []string) tea.Cmd {
+	return func() tea.Msg {
+		return RevisionsLoadedMsg{CommitIds: commitIds}
+	}
+}
+
+func Prefetch(commitIds []string) tea.Cmd {
+	return func() tea.Msg {
+		return PrefetchMsg{CommitIds: commitIds}
+	}
+}
+
 func Refresh() tea.Msg {
 	return RefreshMsg{}
 }
 
+func RefreshTags() tea.Msg {
+	return RefreshMsg{Tags: true}
+}
+
 func ToggleHelp() tea.Msg {
 	return ToggleHelpMsg{}
 }
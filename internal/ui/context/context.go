@@ -8,9 +8,17 @@ import (
 
 type AppContext interface {
 	KeyMap() config.KeyMappings[key.Binding]
+	// Location is the root of the repository, empty when it's not known
+	Location() string
 	SelectedItem() SelectedItem
 	SetSelectedItem(item SelectedItem) tea.Cmd
 	RunCommandImmediate(args []string) ([]byte, error)
 	RunCommand(args []string, continuations ...tea.Cmd) tea.Cmd
 	RunInteractiveCommand(args []string, continuation tea.Cmd) tea.Cmd
+	// RunInteractiveCommandWithCleanup calls cleanup once the command exits, also when it fails and the continuation is skipped
+	RunInteractiveCommandWithCleanup(args []string, cleanup func(), continuation tea.Cmd) tea.Cmd
+	// RunFormatter pipes the input through an external program, args[0] is the program
+	RunFormatter(args []string, input []byte) ([]byte, error)
+	// RunPager hands the input to an interactive external program, args[0] is the program
+	RunPager(args []string, input []byte) tea.Cmd
 }
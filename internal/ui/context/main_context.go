@@ -15,29 +15,34 @@ type SelectedItem interface {
 
 type SelectedRevision struct {
 	ChangeId string
+	// CommitId identifies the content of the revision, it changes when the revision is rewritten
+	CommitId string
 }
 
 func (s SelectedRevision) Equal(other SelectedItem) bool {
 	if o, ok := other.(SelectedRevision); ok {
-		return s.ChangeId == o.ChangeId
+		return s.ChangeId == o.ChangeId && s.CommitId == o.CommitId
 	}
 	return false
 }
 
 type SelectedFile struct {
 	ChangeId string
+	CommitId string
 	File     string
 }
 
 func (s SelectedFile) Equal(other SelectedItem) bool {
 	if o, ok := other.(SelectedFile); ok {
-		return s.ChangeId == o.ChangeId && s.File == o.File
+		return s.ChangeId == o.ChangeId && s.CommitId == o.CommitId && s.File == o.File
 	}
 	return false
 }
 
 type SelectedOperation struct {
 	OperationId string
+	// IsCurrent is set for the operation the repository is at
+	IsCurrent bool
 }
 
 func (s SelectedOperation) Equal(other SelectedItem) bool {
@@ -57,6 +62,10 @@ func (a *MainContext) KeyMap() config.KeyMappings[key.Binding] {
 	return a.config.GetKeyMap()
 }
 
+func (a *MainContext) Location() string {
+	return a.location
+}
+
 func (a *MainContext) SelectedItem() SelectedItem {
 	return a.selectedItem
 }
@@ -99,6 +108,10 @@ func (a *MainContext) RunCommand(args []string, continuations ...tea.Cmd) tea.Cm
 }
 
 func (a *MainContext) RunInteractiveCommand(args []string, continuation tea.Cmd) tea.Cmd {
+	return a.RunInteractiveCommandWithCleanup(args, func() {}, continuation)
+}
+
+func (a *MainContext) RunInteractiveCommandWithCleanup(args []string, cleanup func(), continuation tea.Cmd) tea.Cmd {
 	c := exec.Command("jj", args...)
 	errBuffer := &bytes.Buffer{}
 	c.Stderr = errBuffer
@@ -106,6 +119,7 @@ func (a *MainContext) RunInteractiveCommand(args []string, continuation tea.Cmd)
 	return tea.Batch(
 		common.CommandRunning(args),
 		tea.ExecProcess(c, func(err error) tea.Msg {
+			cleanup()
 			if err != nil {
 				return common.CommandCompletedMsg{Err: err, Output: errBuffer.String()}
 			}
@@ -116,6 +130,31 @@ func (a *MainContext) RunInteractiveCommand(args []string, continuation tea.Cmd)
 	)
 }
 
+func (a *MainContext) RunFormatter(args []string, input []byte) ([]byte, error) {
+	c := exec.Command(args[0], args[1:]...)
+	c.Dir = a.location
+	c.Stdin = bytes.NewReader(input)
+	errBuffer := &bytes.Buffer{}
+	c.Stderr = errBuffer
+	output, err := c.Output()
+	if err != nil {
+		return errBuffer.Bytes(), err
+	}
+	return output, nil
+}
+
+func (a *MainContext) RunPager(args []string, input []byte) tea.Cmd {
+	c := exec.Command(args[0], args[1:]...)
+	c.Dir = a.location
+	c.Stdin = bytes.NewReader(input)
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		if err != nil {
+			return common.CommandCompletedMsg{Err: err}
+		}
+		return nil
+	})
+}
+
 func NewAppContext(location string) AppContext {
 	configuration := config.Load()
 	return &MainContext{
@@ -1,26 +1,186 @@
 package diff
 
 import (
-	"github.com/idursun/jjui/internal/ui/common"
+	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+)
+
+const (
+	sidebarMaxWidth = 40
+	// the sidebar is hidden when the terminal is narrower than this
+	sidebarMinTotalWidth = 80
 )
 
+var (
+	MatchStyle        = lipgloss.NewStyle().Reverse(true)
+	CurrentMatchStyle = lipgloss.NewStyle().Background(common.Yellow).Foreground(common.Black)
+	headerStyle       = common.DefaultPalette.Normal.Bold(true)
+	separatorStyle    = common.DefaultPalette.Dimmed
+)
+
+type updateContentMsg struct {
+	output string
+	// files are parsed from the git formatted output before it's passed to the formatter
+	files []jj.DiffFile
+}
+
 type Model struct {
-	view viewport.Model
+	context context.AppContext
+	view    viewport.Model
+	keyMap  config.KeyMappings[key.Binding]
+	// revision and file are set when the diff can be reloaded with other options,
+	// from is set when the revision is compared with another revision instead of its parents
+	from     string
+	revision string
+	file     string
+	output   string
+	files    []jj.DiffFile
+	// external is the formatter or the diff tool that produced the output
+	external string
+	// lines and plain are the rendered lines, index maps the lines of the output to them.
+	// fileLines and hunks are the rendered lines of the files and the hunks, nil when they can't be located.
+	lines     []string
+	plain     []string
+	index     []int
+	fileLines []int
+	hunks     []int
+	searching bool
+	input     textinput.Model
+	query     string
+	matches   []int
+	match     int
+	width     int
+	height    int
 }
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	if m.revision == "" {
+		return nil
+	}
+	return m.load
+}
+
+func (m Model) load() tea.Msg {
+	args := jj.Diff(m.revision, m.file, jj.DiffArgs(m.format())...)
+	if m.from != "" {
+		args = jj.DiffRange(m.from, m.revision, m.file, jj.DiffArgs(m.format())...)
+	}
+	output, err := m.context.RunCommandImmediate(args)
+	if err != nil {
+		return common.CommandCompletedMsg{Output: string(output), Err: err}
+	}
+	formatted, err := Format(m.context, string(output), m.view.Width)
+	if err != nil {
+		return common.CommandCompletedMsg{Output: formatted, Err: err}
+	}
+	msg := updateContentMsg{output: formatted}
+	// the output of a diff tool is not in git format
+	if config.Current.Diff.Tool == "" {
+		msg.files = jj.ParseGitDiff(string(output))
+	}
+	return msg
+}
+
+// format is the configured diff format, the viewer falls back to git format as it's needed to navigate files and hunks
+func (m Model) format() string {
+	if config.Current.Preview.DiffFormat == "" {
+		return "git"
+	}
+	return config.Current.Preview.DiffFormat
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case updateContentMsg:
+		m.setContent(msg.output, msg.files)
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.setSize(msg.Width, msg.Height)
+		return m, nil
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		switch {
+		case key.Matches(msg, m.keyMap.Cancel):
+			if m.query != "" {
+				m.setQuery("")
+				return m, nil
+			}
 			return m, common.Close
+		case key.Matches(msg, m.keyMap.DiffView.NextFile):
+			if i := m.currentFile() + 1; i < len(m.fileLines) {
+				m.view.SetYOffset(m.fileLines[i])
+			}
+			return m, nil
+		case key.Matches(msg, m.keyMap.DiffView.PrevFile):
+			i := m.currentFile()
+			// go to the beginning of the current file first
+			if i >= 0 && m.fileLines[i] < m.view.YOffset {
+				m.view.SetYOffset(m.fileLines[i])
+			} else if i > 0 {
+				m.view.SetYOffset(m.fileLines[i-1])
+			}
+			return m, nil
+		case key.Matches(msg, m.keyMap.DiffView.ToggleSideBySide):
+			config.Current.Preview.SideBySide = !config.Current.Preview.SideBySide
+			m.layout()
+			return m, nil
+		case m.revision != "" && key.Matches(msg, m.keyMap.DiffView.Format):
+			// the default format is shown as git, so skip it to avoid showing the same diff twice
+			config.Current.Preview.DiffFormat = m.format()
+			config.Current.Preview.CycleDiffFormat()
+			if config.Current.Preview.DiffFormat == "" {
+				config.Current.Preview.CycleDiffFormat()
+			}
+			return m, m.load
+		case m.revision != "" && key.Matches(msg, m.keyMap.DiffView.Whitespace):
+			config.Current.Preview.IgnoreWhitespace = !config.Current.Preview.IgnoreWhitespace
+			return m, m.load
+		case m.revision != "" && key.Matches(msg, m.keyMap.DiffView.Context):
+			config.Current.Preview.CycleContextLines()
+			return m, m.load
+		case key.Matches(msg, m.keyMap.DiffView.NextHunk):
+			for _, line := range m.hunks {
+				if line > m.view.YOffset {
+					m.view.SetYOffset(line)
+					break
+				}
+			}
+			return m, nil
+		case key.Matches(msg, m.keyMap.DiffView.PrevHunk):
+			for i := len(m.hunks) - 1; i >= 0; i-- {
+				if m.hunks[i] < m.view.YOffset {
+					m.view.SetYOffset(m.hunks[i])
+					break
+				}
+			}
+			return m, nil
+		case key.Matches(msg, m.keyMap.DiffView.Pager):
+			return m, m.context.RunPager(config.Current.Diff.PagerArgs(), []byte(m.output))
+		case key.Matches(msg, m.keyMap.DiffView.Search):
+			m.searching = true
+			m.input.SetValue(m.query)
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		case key.Matches(msg, m.keyMap.DiffView.NextMatch):
+			m.jumpToMatch(m.match + 1)
+			return m, nil
+		case key.Matches(msg, m.keyMap.DiffView.PrevMatch):
+			m.jumpToMatch(m.match - 1)
+			return m, nil
 		}
 	}
 	var cmd tea.Cmd
@@ -28,14 +188,365 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Cancel):
+		m.searching = false
+		m.input.Blur()
+		return m, nil
+	case key.Matches(msg, m.keyMap.Apply):
+		m.searching = false
+		m.input.Blur()
+		m.setQuery(m.input.Value())
+		m.jumpToMatch(m.firstMatchAfter(m.view.YOffset))
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// setQuery finds the lines matching the query, ignoring case, and highlights them
+func (m *Model) setQuery(query string) {
+	m.query = query
+	m.matches = nil
+	m.match = 0
+	if query != "" {
+		needle := strings.ToLower(query)
+		for i, line := range m.plain {
+			if strings.Contains(strings.ToLower(line), needle) {
+				m.matches = append(m.matches, i)
+			}
+		}
+	}
+	m.render()
+}
+
+func (m *Model) firstMatchAfter(line int) int {
+	for i, match := range m.matches {
+		if match >= line {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m *Model) jumpToMatch(index int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.match = (index + len(m.matches)) % len(m.matches)
+	m.render()
+	// keep a few lines of context above the match
+	m.view.SetYOffset(max(m.matches[m.match]-3, 0))
+}
+
+// currentFile returns the index of the file shown at the top of the view
+func (m *Model) currentFile() int {
+	current := -1
+	for i, start := range m.fileLines {
+		if start > m.view.YOffset {
+			break
+		}
+		current = i
+	}
+	return current
+}
+
+func (m *Model) currentHunk() (int, int) {
+	file := m.currentFile()
+	if file < 0 || m.hunks == nil {
+		return -1, 0
+	}
+	hunks := m.files[file].Hunks
+	current := -1
+	for i, h := range hunks {
+		if m.index[h.Line] > m.view.YOffset {
+			break
+		}
+		current = i
+	}
+	return current, len(hunks)
+}
+
+func (m *Model) setContent(output string, files []jj.DiffFile) {
+	m.output = output
+	m.files = files
+	m.external = External()
+	// the sidebar depends on the files
+	m.setSize(m.width, m.height)
+	m.layout()
+}
+
+func (m Model) sideBySide() bool {
+	return config.Current.Preview.SideBySide && m.external == "" && CanRenderSideBySide(m.files, m.view.Width)
+}
+
+// layout renders the output in the unified or side by side layout and keeps the same part of the output in view
+func (m *Model) layout() {
+	top := 0
+	for i, line := range m.index {
+		if line > m.view.YOffset {
+			break
+		}
+		top = i
+	}
+
+	if m.sideBySide() {
+		m.lines, m.index = RenderSideBySide(m.output, m.files, m.view.Width)
+	} else {
+		m.lines = strings.Split(strings.TrimSuffix(m.output, "\n"), "\n")
+		m.index = make([]int, len(m.lines))
+		for i := range m.index {
+			m.index[i] = i
+		}
+	}
+	m.plain = make([]string, len(m.lines))
+	for i, line := range m.lines {
+		m.plain[i] = ansi.Strip(line)
+	}
+	m.fileLines, m.hunks = nil, nil
+	if m.external != "" {
+		m.fileLines = locateFiles(m.plain, m.files)
+	} else {
+		for _, f := range m.files {
+			m.fileLines = append(m.fileLines, m.index[f.Line])
+			for _, h := range f.Hunks {
+				m.hunks = append(m.hunks, m.index[h.Line])
+			}
+		}
+	}
+	m.setQuery(m.query)
+	if top < len(m.index) {
+		m.view.SetYOffset(m.index[top])
+	}
+}
+
+// locateFiles finds the headers of the files in the output of a formatter by their names, as its layout is unknown.
+// It returns nil when a file can't be found, e.g. when the formatter doesn't show the file names.
+func locateFiles(lines []string, files []jj.DiffFile) []int {
+	var starts []int
+	from := 0
+	for _, f := range files {
+		i := slices.IndexFunc(lines[from:], func(line string) bool { return strings.Contains(line, f.Name) })
+		if i == -1 {
+			return nil
+		}
+		starts = append(starts, from+i)
+		from += i + 1
+	}
+	return starts
+}
+
+func (m *Model) render() {
+	lines := make([]string, len(m.lines))
+	copy(lines, m.lines)
+	for i, line := range m.matches {
+		style := MatchStyle
+		if i == m.match {
+			style = CurrentMatchStyle
+		}
+		lines[line] = Highlight(m.plain[line], m.query, style)
+	}
+	m.view.SetContent(strings.Join(lines, "\n"))
+}
+
+// Highlight renders the matches of the query in the line, ignoring case
+func Highlight(line string, query string, style lipgloss.Style) string {
+	var b strings.Builder
+	lower, needle := strings.ToLower(line), strings.ToLower(query)
+	if len(lower) != len(line) {
+		// lower casing changed the byte offsets, fall back to case sensitive matching
+		lower, needle = line, query
+	}
+	for {
+		idx := strings.Index(lower, needle)
+		if idx == -1 || needle == "" {
+			b.WriteString(line)
+			break
+		}
+		b.WriteString(line[:idx])
+		b.WriteString(style.Render(line[idx : idx+len(needle)]))
+		line, lower = line[idx+len(needle):], lower[idx+len(needle):]
+	}
+	return b.String()
+}
+
+func (m *Model) sidebarWidth() int {
+	if len(m.files) < 2 || m.width < sidebarMinTotalWidth {
+		return 0
+	}
+	return min(sidebarMaxWidth, m.width/4)
+}
+
+func (m *Model) setSize(width int, height int) {
+	m.width, m.height = width, height
+	sidebar := m.sidebarWidth()
+	if sidebar > 0 {
+		sidebar++
+	}
+	wasSideBySide := m.sideBySide()
+	m.view.Width = max(width-sidebar, 0)
+	m.view.Height = max(height-2, 0)
+	// side by side lines are rendered for a specific width
+	if wasSideBySide || m.sideBySide() {
+		m.layout()
+	}
+}
+
 func (m Model) View() string {
-	return m.view.View()
+	content := m.view.View()
+	if sidebar := m.sidebarWidth(); sidebar > 0 {
+		content = lipgloss.JoinHorizontal(0, m.sidebarView(sidebar), separatorStyle.Render(strings.Repeat("│\n", max(m.view.Height-1, 0))+"│"), content)
+	}
+	return lipgloss.JoinVertical(0, m.headerView(), content, m.footerView())
 }
 
-func New(output string, width int, height int) tea.Model {
+func (m Model) headerView() string {
+	var parts []string
+	if m.from != "" {
+		parts = append(parts, common.DefaultPalette.ChangeId.Render(m.from), common.DefaultPalette.Dimmed.Render(" → "), common.DefaultPalette.ChangeId.Render(m.revision), " ")
+	}
+	if file := m.currentFile(); file >= 0 {
+		f := m.files[file]
+		name := f.Name
+		if f.OldName != "" && f.OldName != f.Name {
+			name = fmt.Sprintf("%s → %s", f.OldName, f.Name)
+		}
+		parts = append(parts, headerStyle.Render(name), common.DefaultPalette.Dimmed.Render(fmt.Sprintf(" file %d/%d", file+1, len(m.files))))
+		if hunk, total := m.currentHunk(); total > 0 {
+			parts = append(parts, common.DefaultPalette.Dimmed.Render(fmt.Sprintf(" hunk %d/%d", max(hunk+1, 0), total)))
+		}
+	}
+	if note := m.navigationNote(); note != "" {
+		parts = append(parts, common.DefaultPalette.Dimmed.Render(note))
+	}
+	line := min(m.view.YOffset+1, max(len(m.lines), 1))
+	parts = append(parts, common.DefaultPalette.Dimmed.Render(fmt.Sprintf(" line %d/%d (%.0f%%)", line, len(m.lines), m.view.ScrollPercent()*100)))
+	if m.revision != "" {
+		parts = append(parts, common.DefaultPalette.Dimmed.Render(" "+m.optionsView()))
+	}
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(lipgloss.JoinHorizontal(0, parts...))
+}
+
+// navigationNote explains why the files or the hunks can't be navigated in the output of a formatter or a diff tool
+func (m Model) navigationNote() string {
+	switch {
+	case m.external == "" || len(m.files) == 0 && config.Current.Diff.Tool == "":
+		return ""
+	case m.fileLines == nil:
+		return " no file navigation with " + m.external
+	default:
+		return " no hunk navigation with " + m.external
+	}
+}
+
+func (m Model) optionsView() string {
+	options := []string{m.format()}
+	if m.external != "" {
+		options = []string{m.external}
+	}
+	if config.Current.Preview.IgnoreWhitespace {
+		options = append(options, "ignore whitespace")
+	}
+	if config.Current.Preview.ContextLines > 0 {
+		options = append(options, fmt.Sprintf("context %d", config.Current.Preview.ContextLines))
+	}
+	return "[" + strings.Join(options, ", ") + "]"
+}
+
+func (m Model) sidebarView(width int) string {
+	current := m.currentFile()
+	start := max(0, current-m.view.Height+1)
+	end := min(len(m.files), start+m.view.Height)
+	var lines []string
+	for i := start; i < end; i++ {
+		f := m.files[i]
+		stats := common.DefaultPalette.Added.Render(fmt.Sprintf("+%d", f.Added)) + " " + common.DefaultPalette.Deleted.Render(fmt.Sprintf("-%d", f.Removed))
+		name := ansi.Truncate(f.Name, max(width-lipgloss.Width(stats)-1, 1), "…")
+		style := common.DefaultPalette.Normal
+		if i == current {
+			style = style.Bold(true).Background(common.IntenseBlack)
+		}
+		line := style.Render(fmt.Sprintf("%-*s", width-lipgloss.Width(stats)-1, name)) + " " + stats
+		lines = append(lines, line)
+	}
+	return lipgloss.Place(width, m.view.Height, 0, 0, lipgloss.JoinVertical(0, lines...))
+}
+
+func (m Model) footerView() string {
+	if m.searching {
+		return m.input.View()
+	}
+	var parts []string
+	if m.query != "" {
+		status := fmt.Sprintf("no matches for '%s'", m.query)
+		if len(m.matches) > 0 {
+			status = fmt.Sprintf("match %d/%d for '%s'", m.match+1, len(m.matches), m.query)
+		}
+		parts = append(parts, common.DefaultPalette.Normal.Render(status), " ")
+	}
+	var bindings []key.Binding
+	if m.fileLines != nil {
+		bindings = append(bindings, m.keyMap.DiffView.NextFile, m.keyMap.DiffView.PrevFile)
+	}
+	if m.hunks != nil {
+		bindings = append(bindings, m.keyMap.DiffView.NextHunk, m.keyMap.DiffView.PrevHunk)
+	}
+	bindings = append(bindings, m.keyMap.DiffView.Search)
+	if m.external == "" && CanRenderSideBySide(m.files, m.view.Width) {
+		bindings = append(bindings, m.keyMap.DiffView.ToggleSideBySide)
+	}
+	if m.revision != "" {
+		bindings = append(bindings, m.keyMap.DiffView.Format, m.keyMap.DiffView.Whitespace, m.keyMap.DiffView.Context)
+	}
+	if len(m.matches) > 0 {
+		bindings = append(bindings, m.keyMap.DiffView.NextMatch, m.keyMap.DiffView.PrevMatch)
+	}
+	bindings = append(bindings, m.keyMap.DiffView.Pager, m.keyMap.Cancel)
+	for _, b := range bindings {
+		parts = append(parts, common.DefaultPalette.ChangeId.Render(b.Help().Key+" "), common.DefaultPalette.Dimmed.Render(b.Help().Desc+" "))
+	}
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(lipgloss.JoinHorizontal(0, parts...))
+}
+
+func New(context context.AppContext, output string, width int, height int) tea.Model {
+	return newModel(context, "", "", output, width, height)
+}
+
+// NewForRevision shows the diff of the revision, limited to the file when it's set, using the diff options of the preview config
+func NewForRevision(context context.AppContext, revision string, file string, width int, height int) tea.Model {
+	return newModel(context, revision, file, "", width, height)
+}
+
+// NewForRange shows the differences between the contents of two revisions
+func NewForRange(context context.AppContext, from string, to string, width int, height int) tea.Model {
+	m := newModel(context, to, "", "", width, height)
+	m.from = from
+	return m
+}
+
+func newModel(context context.AppContext, revision string, file string, output string, width int, height int) Model {
+	keyMap := context.KeyMap()
 	view := viewport.New(width, height)
-	view.SetContent(output)
-	return Model{
-		view: view,
+	view.KeyMap.Up = keyMap.Up
+	view.KeyMap.Down = keyMap.Down
+
+	input := textinput.New()
+	input.Prompt = "/"
+	input.PromptStyle = common.DefaultPalette.ChangeId
+
+	m := Model{
+		context:  context,
+		view:     view,
+		keyMap:   keyMap,
+		revision: revision,
+		file:     file,
+		output:   output,
+		files:    jj.ParseGitDiff(output),
+		input:    input,
 	}
+	m.setSize(width, height)
+	m.layout()
+	return m
 }
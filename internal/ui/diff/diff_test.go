@@ -0,0 +1,184 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func gitDiff() string {
+	var b strings.Builder
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		b.WriteString("diff --git a/" + name + " b/" + name + "\n")
+		b.WriteString("--- a/" + name + "\n+++ b/" + name + "\n")
+		for _, start := range []string{"1", "20"} {
+			b.WriteString("@@ -" + start + ",2 +" + start + ",2 @@\n")
+			b.WriteString(" context\n-old " + name + "\n+new " + name + "\n")
+		}
+	}
+	return b.String()
+}
+
+func press(m tea.Model, keys ...string) tea.Model {
+	for _, k := range keys {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+	return m
+}
+
+func TestFileAndHunkNavigation(t *testing.T) {
+	c := test.NewTestContext(t)
+	m := New(c, gitDiff(), 100, 5)
+
+	m = press(m, "}")
+	assert.Equal(t, 11, m.(Model).view.YOffset)
+	assert.Contains(t, m.View(), "file 2/3")
+
+	m = press(m, "]", "]")
+	assert.Equal(t, 18, m.(Model).view.YOffset)
+	assert.Contains(t, m.View(), "hunk 2/2")
+
+	m = press(m, "[")
+	assert.Equal(t, 14, m.(Model).view.YOffset)
+
+	m = press(m, "{")
+	assert.Equal(t, 11, m.(Model).view.YOffset)
+	m = press(m, "{")
+	assert.Equal(t, 0, m.(Model).view.YOffset)
+}
+
+func TestSearch(t *testing.T) {
+	c := test.NewTestContext(t)
+	m := New(c, gitDiff(), 100, 5)
+
+	m = press(m, "/", "N", "e", "w", " ", "b")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Len(t, m.(Model).matches, 2)
+	assert.Contains(t, m.View(), "match 1/2 for 'New b'")
+
+	m = press(m, "n")
+	assert.Equal(t, 1, m.(Model).match)
+	m = press(m, "n")
+	assert.Equal(t, 0, m.(Model).match)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Empty(t, m.(Model).matches)
+}
+
+func TestToggleSideBySide(t *testing.T) {
+	defer func() { config.Current.Preview.SideBySide = false }()
+	c := test.NewTestContext(t)
+	m := New(c, gitDiff(), 140, 10)
+	m = press(m, "}")
+	assert.False(t, m.(Model).sideBySide())
+
+	m = press(m, "s")
+	assert.True(t, m.(Model).sideBySide())
+	// removed and added lines share a row, so the second file starts earlier
+	assert.Equal(t, 9, m.(Model).view.YOffset)
+	assert.Contains(t, m.View(), "file 2/3")
+
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+	assert.False(t, m.(Model).sideBySide())
+	assert.Equal(t, 11, m.(Model).view.YOffset)
+}
+
+func TestCycleFormatReloadsRevisionDiff(t *testing.T) {
+	defer func() { config.Current.Preview = config.PreviewConfig{} }()
+	c := test.NewTestContext(t)
+	c.Expect(jj.Diff("abc", "a.go", "--git")).SetOutput([]byte(gitDiff()))
+	c.Expect(jj.Diff("abc", "a.go", "--color-words")).SetOutput([]byte("Modified regular file a.go:\n"))
+	c.Expect(jj.Diff("abc", "a.go", "--color-words", "--ignore-all-space")).SetOutput([]byte("Modified regular file a.go:\n"))
+	defer c.Verify()
+
+	m := NewForRevision(c, "abc", "a.go", 100, 10)
+	m, _ = m.Update(m.Init()())
+	assert.Len(t, m.(Model).files, 3)
+	assert.Contains(t, m.View(), "[git]")
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	m, _ = m.Update(cmd())
+	assert.Empty(t, m.(Model).files)
+	assert.Equal(t, "color-words", config.Current.Preview.DiffFormat)
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	m, _ = m.Update(cmd())
+	assert.Contains(t, m.View(), "[color-words, ignore whitespace]")
+}
+
+func TestCycleFormatSkipsDefault(t *testing.T) {
+	defer func() { config.Current.Preview = config.PreviewConfig{} }()
+	config.Current.Preview.DiffFormat = "summary"
+	c := test.NewTestContext(t)
+	c.Expect(jj.Diff("abc", "", "--git"))
+	defer c.Verify()
+
+	m := NewForRevision(c, "abc", "", 100, 10)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	cmd()
+	assert.Equal(t, "git", config.Current.Preview.DiffFormat)
+}
+
+func TestFormatterIsAppliedToRevisionDiff(t *testing.T) {
+	defer func() { config.Current.Diff = config.DiffConfig{} }()
+	config.Current.Diff.Formatter = []string{"delta", "--width", "{width}"}
+	c := test.NewTestContext(t)
+	c.Expect(jj.Diff("abc", "", "--git")).SetOutput([]byte(gitDiff()))
+	c.Expect([]string{"delta", "--width", "100"}).SetOutput([]byte("formatted by delta"))
+	c.Expect([]string{"less", "-R"})
+	defer c.Verify()
+
+	config.Current.Diff.Pager = []string{"less", "-R"}
+	m := NewForRevision(c, "abc", "", 100, 10)
+	m, _ = m.Update(m.Init()())
+	assert.Contains(t, m.View(), "formatted by delta")
+	assert.Contains(t, m.View(), "[delta]")
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	cmd()
+}
+
+func TestFormatterKeepsFileNavigation(t *testing.T) {
+	defer func() { config.Current.Diff = config.DiffConfig{} }()
+	config.Current.Diff.Formatter = []string{"delta"}
+	c := test.NewTestContext(t)
+	c.Expect(jj.Diff("abc", "", "--git")).SetOutput([]byte(gitDiff()))
+	c.Expect([]string{"delta"}).SetOutput([]byte("a.go\n1\n2\n3\nb.go\n1\n2\n3\nc.go\n1\n2\n3\n"))
+	defer c.Verify()
+
+	m := NewForRevision(c, "abc", "", 100, 5)
+	m, _ = m.Update(m.Init()())
+	assert.Len(t, m.(Model).files, 3)
+	m = press(m, "}", "}")
+	assert.Equal(t, 8, m.(Model).view.YOffset)
+	assert.Contains(t, m.View(), "file 3/3 no hunk navigation with delta")
+}
+
+func TestDiffToolDisablesNavigation(t *testing.T) {
+	defer func() { config.Current.Diff = config.DiffConfig{} }()
+	config.Current.Diff.Tool = "difft"
+	c := test.NewTestContext(t)
+	c.Expect(jj.Diff("abc", "", "--tool", "difft")).SetOutput([]byte("a.go --- 1/3 --- Go\n1 old 1 new\n"))
+	defer c.Verify()
+
+	m := NewForRevision(c, "abc", "", 100, 5)
+	m, _ = m.Update(m.Init()())
+	assert.Empty(t, m.(Model).files)
+	assert.Contains(t, m.View(), "no file navigation with difft")
+}
+
+func TestCompareTwoRevisions(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.DiffRange("abc", "def", "", "--git")).SetOutput([]byte(gitDiff()))
+	defer c.Verify()
+
+	m := NewForRange(c, "abc", "def", 100, 10)
+	m, _ = m.Update(m.Init()())
+	assert.Len(t, m.(Model).files, 3)
+	assert.Contains(t, m.View(), "abc → def")
+}
@@ -0,0 +1,29 @@
+package diff
+
+import (
+	"github.com/charmbracelet/x/ansi"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/ui/context"
+)
+
+// Format pipes the diff output through the configured external formatter, if there is one.
+// Colors are removed before formatting as formatters apply their own.
+func Format(context context.AppContext, output string, width int) (string, error) {
+	if len(config.Current.Diff.Formatter) == 0 {
+		return output, nil
+	}
+	formatted, err := context.RunFormatter(config.Current.Diff.FormatterArgs(width), []byte(ansi.Strip(output)))
+	return string(formatted), err
+}
+
+// External returns the formatter or the diff tool that produces the shown diffs, if there is one.
+// Their output isn't in git format, so it can't be laid out side by side and its hunks can't be located.
+func External() string {
+	if config.Current.Diff.Tool != "" {
+		return config.Current.Diff.Tool
+	}
+	if len(config.Current.Diff.Formatter) > 0 {
+		return config.Current.Diff.Formatter[0]
+	}
+	return ""
+}
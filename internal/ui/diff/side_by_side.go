@@ -0,0 +1,127 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+)
+
+// SideBySideMinWidth is the narrowest width the side by side layout is used at, narrower views fall back to unified
+const SideBySideMinWidth = 100
+
+var gutterStyle = common.DefaultPalette.Dimmed
+
+type row struct {
+	left  *jj.DiffLine
+	right *jj.DiffLine
+}
+
+// CanRenderSideBySide reports whether the output contains hunks that can be laid out side by side at the given width
+func CanRenderSideBySide(files []jj.DiffFile, width int) bool {
+	return len(files) > 0 && width >= SideBySideMinWidth
+}
+
+// RenderSideBySide lays out the hunks of the parsed files in two columns with line numbers.
+// Lines outside hunks (e.g. file headers) span both columns. The returned index maps
+// each line of the output to its rendered line.
+func RenderSideBySide(output string, files []jj.DiffFile, width int) ([]string, []int) {
+	original := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	hunks := make(map[int]*jj.DiffHunk)
+	numberWidths := make(map[int]int)
+	for _, f := range files {
+		w := 1
+		for i := range f.Hunks {
+			h := &f.Hunks[i]
+			hunks[h.Line] = h
+			w = max(w, len(fmt.Sprint(h.OldStart+h.OldCount)), len(fmt.Sprint(h.NewStart+h.NewCount)))
+		}
+		for _, h := range f.Hunks {
+			numberWidths[h.Line] = w
+		}
+	}
+
+	var lines []string
+	index := make([]int, len(original))
+	for i := 0; i < len(original); i++ {
+		index[i] = len(lines)
+		lines = append(lines, original[i])
+		hunk, ok := hunks[i]
+		if !ok {
+			continue
+		}
+		for _, r := range pairLines(hunk.Lines) {
+			if r.left != nil {
+				index[r.left.Line] = len(lines)
+				i = max(i, r.left.Line)
+			}
+			if r.right != nil {
+				index[r.right.Line] = len(lines)
+				i = max(i, r.right.Line)
+			}
+			lines = append(lines, renderRow(r, numberWidths[hunk.Line], width))
+		}
+	}
+	return lines, index
+}
+
+// pairLines aligns the lines of a hunk: context lines appear on both sides, and
+// removed lines are paired with the added lines that follow them
+func pairLines(lines []jj.DiffLine) []row {
+	var rows []row
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == jj.DiffContext {
+			rows = append(rows, row{left: &lines[i], right: &lines[i]})
+			i++
+			continue
+		}
+		var removed, added []*jj.DiffLine
+		for ; i < len(lines) && lines[i].Kind == jj.DiffRemoved; i++ {
+			removed = append(removed, &lines[i])
+		}
+		for ; i < len(lines) && lines[i].Kind == jj.DiffAdded; i++ {
+			added = append(added, &lines[i])
+		}
+		for j := 0; j < max(len(removed), len(added)); j++ {
+			var r row
+			if j < len(removed) {
+				r.left = removed[j]
+			}
+			if j < len(added) {
+				r.right = added[j]
+			}
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+func renderRow(r row, numberWidth int, width int) string {
+	columnWidth := (width - 1) / 2
+	left := renderCell(r.left, r.left != nil && r.left.Kind == jj.DiffRemoved, true, numberWidth, columnWidth)
+	right := renderCell(r.right, r.right != nil && r.right.Kind == jj.DiffAdded, false, numberWidth, columnWidth)
+	return left + gutterStyle.Render("│") + right
+}
+
+func renderCell(line *jj.DiffLine, changed bool, old bool, numberWidth int, width int) string {
+	contentWidth := max(width-numberWidth-1, 1)
+	if line == nil {
+		return strings.Repeat(" ", numberWidth+1+contentWidth)
+	}
+	number := line.NewNumber
+	style := common.DefaultPalette.Normal
+	if old {
+		number = line.OldNumber
+	}
+	if changed && old {
+		style = common.DefaultPalette.Deleted
+	} else if changed {
+		style = common.DefaultPalette.Added
+	}
+	content := ansi.Truncate(strings.ReplaceAll(line.Content, "\t", "    "), contentWidth, "…")
+	content += strings.Repeat(" ", max(contentWidth-lipgloss.Width(content), 0))
+	return gutterStyle.Render(fmt.Sprintf("%*d ", numberWidth, number)) + style.Render(content)
+}
@@ -0,0 +1,43 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/stretchr/testify/assert"
+)
+
+const hunk = "diff --git a/a.go b/a.go\n" +
+	"@@ -1,4 +1,3 @@\n" +
+	" same\n" +
+	"-old one\n" +
+	"-old two\n" +
+	"+new one\n" +
+	" tail\n"
+
+func TestRenderSideBySide(t *testing.T) {
+	lines, index := RenderSideBySide(hunk, jj.ParseGitDiff(hunk), 41)
+	var plain []string
+	for _, line := range lines {
+		plain = append(plain, strings.TrimRight(ansi.Strip(line), " "))
+	}
+	assert.Equal(t, []string{
+		"diff --git a/a.go b/a.go",
+		"@@ -1,4 +1,3 @@",
+		"1 same              │1 same",
+		"2 old one           │2 new one",
+		"3 old two           │",
+		"4 tail              │3 tail",
+	}, plain)
+	// removed and added lines that are paired share a row
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 3, 5}, index)
+}
+
+func TestCanRenderSideBySide(t *testing.T) {
+	files := jj.ParseGitDiff(hunk)
+	assert.True(t, CanRenderSideBySide(files, SideBySideMinWidth))
+	assert.False(t, CanRenderSideBySide(files, SideBySideMinWidth-1))
+	assert.False(t, CanRenderSideBySide(nil, SideBySideMinWidth))
+}
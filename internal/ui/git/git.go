@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/confirmation"
 	"github.com/idursun/jjui/internal/ui/context"
+	"slices"
 	"strings"
 )
 
@@ -21,6 +24,9 @@ type item struct {
 	name    string
 	desc    string
 	command []string
+	// prompts are asked in order before running the item, their values are passed to withInput
+	prompts   []string
+	withInput func(values ...string) jj.CommandArgs
 }
 
 func (i item) FilterValue() string {
@@ -35,14 +41,31 @@ func (i item) Description() string {
 	return i.desc
 }
 
+type pushPreviewMsg struct {
+	args    jj.CommandArgs
+	changes []jj.PushChange
+}
+
+type confirmProtectedMsg struct {
+	args      jj.CommandArgs
+	protected []string
+}
+
 type Model struct {
-	context context.AppContext
-	keymap  config.KeyMappings[key.Binding]
-	list    list.Model
-	items   []list.Item
-	filter  string
-	width   int
-	height  int
+	context      context.AppContext
+	keymap       config.KeyMappings[key.Binding]
+	list         list.Model
+	items        []list.Item
+	filter       string
+	prompting    *item
+	values       []string
+	input        textinput.Model
+	confirmation tea.Model
+	summary      string
+	commit       *jj.Commit
+	stack        *stackModel
+	width        int
+	height       int
 }
 
 func (m *Model) Width() int {
@@ -70,14 +93,55 @@ func (m *Model) Init() tea.Cmd {
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.prompting != nil {
+		return m.updatePrompt(msg)
+	}
 	switch msg := msg.(type) {
+	case updateStackMsg:
+		if m.stack != nil {
+			var cmd tea.Cmd
+			m.stack, cmd = m.stack.Update(msg)
+			return m, cmd
+		}
+	case closeStackMsg:
+		m.stack = nil
+		return m, nil
+	case pushStackMsg:
+		return m, m.previewPush(msg.args)
+	case pushPreviewMsg:
+		return m, m.showPushPreview(msg)
+	case confirmProtectedMsg:
+		return m, m.confirmProtected(msg)
+	case confirmation.CloseMsg:
+		m.confirmation = nil
+		m.summary = ""
+		return m, nil
 	case tea.KeyMsg:
+		if m.confirmation != nil {
+			var cmd tea.Cmd
+			m.confirmation, cmd = m.confirmation.Update(msg)
+			return m, cmd
+		}
+		if m.stack != nil {
+			var cmd tea.Cmd
+			m.stack, cmd = m.stack.Update(msg)
+			return m, cmd
+		}
 		if m.list.SettingFilter() {
 			break
 		}
 		switch {
 		case key.Matches(msg, m.keymap.Apply):
+			if m.list.SelectedItem() == nil {
+				break
+			}
 			action := m.list.SelectedItem().(item)
+			if len(action.prompts) > 0 {
+				return m, m.startPrompt(action)
+			}
+			if isPush(action.command) {
+				return m, m.previewPush(action.command)
+			}
 			return m, m.context.RunCommand(jj.Args(action.command...), common.Refresh, common.Close)
 		case key.Matches(msg, m.keymap.Cancel):
 			if m.filter != "" || m.list.IsFiltered() {
@@ -89,6 +153,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.filtered("push")
 		case key.Matches(msg, m.keymap.Git.Fetch):
 			return m.filtered("fetch")
+		case key.Matches(msg, m.keymap.Git.Remote):
+			return m.filtered("git remote")
+		case key.Matches(msg, m.keymap.Git.Stack) && m.commit != nil:
+			m.stack = newStackModel(m.context, m.commit.GetChangeId())
+			return m, m.stack.load
 		}
 	}
 	var cmd tea.Cmd
@@ -96,6 +165,128 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func isPush(args []string) bool {
+	return len(args) >= 2 && args[0] == "git" && args[1] == "push"
+}
+
+// previewPush runs the push with --dry-run so that the changes can be reviewed before anything is sent to the remote
+func (m *Model) previewPush(args jj.CommandArgs) tea.Cmd {
+	return func() tea.Msg {
+		dryRun := append(slices.Clone(args), "--dry-run")
+		output, err := m.context.RunCommandImmediate(dryRun)
+		if err != nil {
+			return common.CommandCompletedMsg{Output: string(output), Err: err}
+		}
+		return pushPreviewMsg{args: args, changes: jj.ParsePushDryRunOutput(string(output))}
+	}
+}
+
+func (m *Model) showPushPreview(msg pushPreviewMsg) tea.Cmd {
+	if len(msg.changes) == 0 {
+		model := confirmation.New("Nothing to push.")
+		model.AddOption("Close", confirmation.Close, key.NewBinding(key.WithKeys("esc")))
+		m.confirmation = &model
+		return nil
+	}
+
+	var lines []string
+	var protected []string
+	for _, change := range msg.changes {
+		lines = append(lines, renderPushChange(change))
+		if config.Current.Git.IsProtected(change.Bookmark) && !slices.Contains(protected, change.Bookmark) {
+			protected = append(protected, change.Bookmark)
+		}
+	}
+	m.summary = lipgloss.JoinVertical(0, lines...)
+
+	yes := m.context.RunCommand(msg.args, common.Refresh, common.Close)
+	if len(protected) > 0 {
+		yes = func() tea.Msg {
+			return confirmProtectedMsg{args: msg.args, protected: protected}
+		}
+	}
+	model := confirmation.New("Are you sure you want to push?")
+	model.AddOption("Yes", yes, key.NewBinding(key.WithKeys("y")))
+	model.AddOption("No", confirmation.Close, key.NewBinding(key.WithKeys("n", "esc")))
+	m.confirmation = &model
+	return m.confirmation.Init()
+}
+
+func (m *Model) confirmProtected(msg confirmProtectedMsg) tea.Cmd {
+	verb := "is"
+	if len(msg.protected) > 1 {
+		verb = "are"
+	}
+	message := fmt.Sprintf("%s %s protected. Push anyway?", strings.Join(msg.protected, ", "), verb)
+	model := confirmation.New(message)
+	model.SetBorderStyle(lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(common.Red).Padding(0, 1, 0, 1))
+	model.AddOption("Push", m.context.RunCommand(msg.args, common.Refresh, common.Close), key.NewBinding(key.WithKeys("P")))
+	model.AddOption("Cancel", confirmation.Close, key.NewBinding(key.WithKeys("n", "esc")))
+	m.confirmation = &model
+	return m.confirmation.Init()
+}
+
+func renderPushChange(change jj.PushChange) string {
+	var marker, desc string
+	style := common.DefaultPalette.Modified
+	switch change.Kind {
+	case jj.PushAdd:
+		marker, desc, style = "+", fmt.Sprintf("add to %s", change.To), common.DefaultPalette.Added
+	case jj.PushMoveForward:
+		marker, desc = "→", fmt.Sprintf("move forward %s → %s", change.From, change.To)
+	case jj.PushMoveSideways:
+		marker, desc, style = "!", fmt.Sprintf("force (sideways) %s → %s", change.From, change.To), common.DefaultPalette.Deleted
+	case jj.PushMoveBackward:
+		marker, desc, style = "!", fmt.Sprintf("force (backward) %s → %s", change.From, change.To), common.DefaultPalette.Deleted
+	case jj.PushDelete:
+		marker, desc, style = "-", fmt.Sprintf("delete from %s", change.From), common.DefaultPalette.Deleted
+	}
+	bookmark := change.Bookmark
+	if change.Remote != "" {
+		bookmark += "@" + change.Remote
+	}
+	if config.Current.Git.IsProtected(change.Bookmark) {
+		bookmark += " (protected)"
+	}
+	return lipgloss.JoinHorizontal(0, "  ", style.Render(marker, bookmark), " ", common.DefaultPalette.Dimmed.Render(desc))
+}
+
+func (m *Model) startPrompt(action item) tea.Cmd {
+	m.prompting = &action
+	m.values = nil
+	m.input.Reset()
+	m.input.Prompt = action.prompts[0] + ": "
+	return m.input.Focus()
+}
+
+func (m *Model) updatePrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keymap.Cancel):
+			m.prompting = nil
+			m.input.Blur()
+			return m, nil
+		case key.Matches(msg, m.keymap.Apply):
+			value := strings.TrimSpace(m.input.Value())
+			if value == "" {
+				return m, nil
+			}
+			m.values = append(m.values, value)
+			if len(m.values) < len(m.prompting.prompts) {
+				m.input.Reset()
+				m.input.Prompt = m.prompting.prompts[len(m.values)] + ": "
+				return m, nil
+			}
+			args := m.prompting.withInput(m.values...)
+			m.prompting = nil
+			return m, m.context.RunCommand(args, common.Refresh, common.Close)
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
 func (m *Model) View() string {
 	title := m.list.Styles.Title.Render(m.list.Title)
 	filterView := lipgloss.JoinHorizontal(0, filterStyle.Render("Showing "), filterValueStyle.Render("all"))
@@ -103,6 +294,15 @@ func (m *Model) View() string {
 		filterView = lipgloss.JoinHorizontal(0, filterStyle.Render("Showing only "), filterValueStyle.Render(m.filter))
 	}
 	listView := m.list.View()
+	if m.stack != nil {
+		listView = lipgloss.JoinVertical(0, "", m.stack.View(m.width-8, m.height-10))
+	}
+	if m.confirmation != nil {
+		listView = lipgloss.JoinVertical(0, "", m.summary, "", m.confirmation.View())
+	}
+	if m.prompting != nil {
+		listView = lipgloss.JoinVertical(0, "", filterValueStyle.PaddingLeft(2).Render(m.prompting.name), "", "  "+m.input.View())
+	}
 	helpView := m.helpView()
 	content := lipgloss.JoinVertical(0, title, "", filterView, listView, "", helpView)
 	content = lipgloss.Place(m.width, m.height, 0, 0, content)
@@ -117,12 +317,22 @@ func renderKey(k key.Binding) string {
 }
 
 func (m *Model) helpView() string {
-	if m.list.SettingFilter() {
+	if m.list.SettingFilter() || m.confirmation != nil {
 		return ""
 	}
+	if m.stack != nil {
+		return m.stack.helpView()
+	}
+	if m.prompting != nil {
+		return " " + lipgloss.JoinHorizontal(0, renderKey(m.keymap.Apply), renderKey(m.keymap.Cancel))
+	}
 	bindings := []string{
 		renderKey(m.keymap.Git.Push),
 		renderKey(m.keymap.Git.Fetch),
+		renderKey(m.keymap.Git.Remote),
+	}
+	if m.commit != nil {
+		bindings = append(bindings, renderKey(m.keymap.Git.Stack))
 	}
 	if m.list.IsFiltered() {
 		bindings = append(bindings, renderKey(m.keymap.Cancel))
@@ -148,16 +358,45 @@ func (m *Model) filtered(filter string) (tea.Model, tea.Cmd) {
 	return m, m.list.SetItems(filtered)
 }
 
-func NewModel(c context.AppContext, commit *jj.Commit, width int, height int) *Model {
+func NewModel(c context.AppContext, commit *jj.Commit, selected []*jj.Commit, width int, height int) *Model {
 	var items []list.Item
+	var bookmarks []jj.Bookmark
+	if commit != nil {
+		items = append(items, item{
+			name:    fmt.Sprintf("git push --change %s", commit.GetChangeId()),
+			desc:    "Push the revision with an auto-generated bookmark",
+			command: jj.GitPush("--change", commit.GetChangeId()),
+		})
+	}
+	var changeIds []string
+	var changeFlags []string
+	for _, s := range selected {
+		if s == nil {
+			continue
+		}
+		changeIds = append(changeIds, s.GetChangeId())
+		changeFlags = append(changeFlags, "--change", s.GetChangeId())
+	}
+	// a selection of only the revision at the cursor is already covered by the item above
+	if len(changeIds) > 1 || (len(changeIds) == 1 && (commit == nil || changeIds[0] != commit.GetChangeId())) {
+		desc := fmt.Sprintf("Push %d selected revisions with auto-generated bookmarks", len(changeIds))
+		if len(changeIds) == 1 {
+			desc = "Push the selected revision with an auto-generated bookmark"
+		}
+		items = append(items, item{
+			name:    fmt.Sprintf("git push --change %s", strings.Join(changeIds, " --change ")),
+			desc:    desc,
+			command: jj.GitPush(changeFlags...),
+		})
+	}
 	if commit != nil {
 		bytes, _ := c.RunCommandImmediate(jj.BookmarkList(commit.GetChangeId()))
-		bookmarks := jj.ParseBookmarkListOutput(string(bytes))
-		for _, b := range bookmarks {
+		for _, b := range jj.ParseBookmarkListOutput(string(bytes)) {
 			if b.Remote {
 				continue
 			}
 			b.Name = strings.TrimSuffix(b.Name, "*")
+			bookmarks = append(bookmarks, b)
 			items = append(items, item{
 				name:    fmt.Sprintf("git push --bookmark %s", b.Name),
 				desc:    "Git push bookmark " + b.Name,
@@ -165,15 +404,75 @@ func NewModel(c context.AppContext, commit *jj.Commit, width int, height int) *M
 			})
 		}
 	}
+	output, _ := c.RunCommandImmediate(jj.GitRemoteList())
+	remotes := jj.ParseRemoteListOutput(string(output))
+
 	items = append(items,
 		item{name: "git push", desc: "Push tracking bookmarks in the current revset", command: jj.GitPush()},
 		item{name: "git push --all", desc: "Push all bookmarks (including new and deleted bookmarks)", command: jj.GitPush("--all")},
 		item{name: "git push --deleted", desc: "Push all deleted bookmarks", command: jj.GitPush("--deleted")},
 		item{name: "git push --tracked", desc: "Push all tracked bookmarks (including deleted bookmarks)", command: jj.GitPush("--tracked")},
 		item{name: "git push --allow-new", desc: "Allow pushing new bookmarks", command: jj.GitPush("--allow-new")},
+	)
+	for _, r := range remotes {
+		for _, b := range bookmarks {
+			items = append(items, item{
+				name:    fmt.Sprintf("git push --bookmark %s --remote %s", b.Name, r.Name),
+				desc:    fmt.Sprintf("Git push bookmark %s to %s", b.Name, r.Name),
+				command: jj.GitPush("--bookmark", b.Name, "--remote", r.Name),
+			})
+		}
+		items = append(items,
+			item{name: fmt.Sprintf("git push --remote %s", r.Name), desc: "Push tracking bookmarks in the current revset to " + r.Name, command: jj.GitPush("--remote", r.Name)},
+			item{name: fmt.Sprintf("git push --all --remote %s", r.Name), desc: "Push all bookmarks to " + r.Name, command: jj.GitPush("--all", "--remote", r.Name)},
+		)
+	}
+	items = append(items,
 		item{name: "git fetch", desc: "Fetch from remote", command: jj.GitFetch()},
 		item{name: "git fetch --all-remotes", desc: "Fetch from all remotes", command: jj.GitFetch("--all-remotes")},
 	)
+	for _, r := range remotes {
+		items = append(items,
+			item{name: fmt.Sprintf("git fetch --remote %s", r.Name), desc: "Fetch from " + r.Url, command: jj.GitFetch("--remote", r.Name)},
+			item{
+				name:    fmt.Sprintf("git fetch --remote %s --branch", r.Name),
+				desc:    fmt.Sprintf("Fetch only bookmarks matching a pattern (e.g. glob:feature/*) from %s", r.Name),
+				prompts: []string{"branch pattern"},
+				withInput: func(values ...string) jj.CommandArgs {
+					return jj.GitFetch("--remote", r.Name, "--branch", values[0])
+				},
+			},
+		)
+	}
+	items = append(items, item{
+		name:    "git remote add",
+		desc:    "Add a new remote",
+		prompts: []string{"name", "url"},
+		withInput: func(values ...string) jj.CommandArgs {
+			return jj.GitRemoteAdd(values[0], values[1])
+		},
+	})
+	for _, r := range remotes {
+		items = append(items,
+			item{name: fmt.Sprintf("git remote remove %s", r.Name), desc: "Remove " + r.Url, command: jj.GitRemoteRemove(r.Name)},
+			item{
+				name:    fmt.Sprintf("git remote rename %s", r.Name),
+				desc:    "Rename " + r.Name,
+				prompts: []string{"new name"},
+				withInput: func(values ...string) jj.CommandArgs {
+					return jj.GitRemoteRename(r.Name, values[0])
+				},
+			},
+			item{
+				name:    fmt.Sprintf("git remote set-url %s", r.Name),
+				desc:    "Change url of " + r.Name + " (" + r.Url + ")",
+				prompts: []string{"url"},
+				withInput: func(values ...string) jj.CommandArgs {
+					return jj.GitRemoteSetUrl(r.Name, values[0])
+				},
+			},
+		)
+	}
 	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
 	l.SetShowTitle(true)
 	l.Title = "Git Operations"
@@ -184,10 +483,14 @@ func NewModel(c context.AppContext, commit *jj.Commit, width int, height int) *M
 	l.SetFilteringEnabled(true)
 	l.SetShowHelp(false)
 	l.DisableQuitKeybindings()
+	input := textinput.New()
+	input.PromptStyle = common.DefaultPalette.ChangeId
 	m := &Model{
 		context: c,
 		list:    l,
 		items:   items,
+		input:   input,
+		commit:  commit,
 		keymap:  c.KeyMap(),
 	}
 	m.SetWidth(width)
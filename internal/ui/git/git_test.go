@@ -1,31 +1,45 @@
 package git
 
 import (
+	"bytes"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/test"
 	"testing"
 	"time"
 )
 
+const dryRunOutput = `Changes to push to origin:
+  Move forward bookmark main from 0f8a1c3b0cdb to 7c4d0e2a9f3b
+Dry-run requested, not pushing.`
+
 func Test_Push(t *testing.T) {
 	c := test.NewTestContext(t)
+	c.Expect(jj.GitRemoteList())
+	c.Expect(jj.GitPush("--dry-run")).SetOutput([]byte(dryRunOutput))
 	c.Expect(jj.GitPush())
 	defer c.Verify()
 
-	op := NewModel(c, nil, 0, 0)
+	op := NewModel(c, nil, nil, 0, 0)
 	tm := teatest.NewTestModel(t, test.NewShell(op))
 	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("Are you sure you want to push?"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
 	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
 }
 
 func Test_Fetch(t *testing.T) {
 	c := test.NewTestContext(t)
+	c.Expect(jj.GitRemoteList())
 	c.Expect(jj.GitFetch())
 	defer c.Verify()
 
-	op := NewModel(c, nil, 0, 0)
+	op := NewModel(c, nil, nil, 0, 0)
 	tm := teatest.NewTestModel(t, test.NewShell(op))
 	tm.Type("/")
 	tm.Type("fetch")
@@ -33,3 +47,179 @@ func Test_Fetch(t *testing.T) {
 	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
 	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
 }
+
+func Test_PushToRemote(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.GitRemoteList()).SetOutput([]byte("origin https://example.com/origin.git\nupstream https://example.com/upstream.git"))
+	c.Expect(jj.GitPush("--remote", "upstream", "--dry-run")).SetOutput([]byte(dryRunOutput))
+	c.Expect(jj.GitPush("--remote", "upstream"))
+	defer c.Verify()
+
+	op := NewModel(c, nil, nil, 0, 0)
+	tm := teatest.NewTestModel(t, test.NewShell(op))
+	tm.Type("/")
+	tm.Type("push --remote upstream")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("Are you sure you want to push?"))
+	})
+	tm.Type("y")
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func Test_PushProtectedNeedsExtraConfirmation(t *testing.T) {
+	config.Current.Git.ProtectedBookmarks = []string{"main", "release/*"}
+	defer func() { config.Current.Git.ProtectedBookmarks = []string{} }()
+
+	c := test.NewTestContext(t)
+	c.Expect(jj.GitRemoteList())
+	c.Expect(jj.GitPush("--dry-run")).SetOutput([]byte(dryRunOutput))
+	c.Expect(jj.GitPush())
+	defer c.Verify()
+
+	op := NewModel(c, nil, nil, 0, 0)
+	tm := teatest.NewTestModel(t, test.NewShell(op))
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("Are you sure you want to push?"))
+	})
+	tm.Type("y")
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("main is protected"))
+	})
+	tm.Type("P")
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func Test_RemoteRename(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.GitRemoteList()).SetOutput([]byte("origin https://example.com/origin.git"))
+	c.Expect(jj.GitRemoteRename("origin", "upstream"))
+	defer c.Verify()
+
+	op := NewModel(c, nil, nil, 0, 0)
+	tm := teatest.NewTestModel(t, test.NewShell(op))
+	tm.Type("/")
+	tm.Type("remote rename")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Type("upstream")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func Test_PushChangeOfSelectedRevisions(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.BookmarkList("current"))
+	c.Expect(jj.GitRemoteList())
+	c.Expect(jj.GitPush("--change", "first", "--change", "second", "--dry-run")).SetOutput([]byte(dryRunOutput))
+	c.Expect(jj.GitPush("--change", "first", "--change", "second"))
+	defer c.Verify()
+
+	selected := []*jj.Commit{{ChangeId: "first"}, {ChangeId: "second"}}
+	op := NewModel(c, &jj.Commit{ChangeId: "current"}, selected, 0, 0)
+	tm := teatest.NewTestModel(t, test.NewShell(op))
+	tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("Are you sure you want to push?"))
+	})
+	tm.Type("y")
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func Test_PushChangeOfSingleSelectedRevision(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.BookmarkList("current"))
+	c.Expect(jj.GitRemoteList())
+	c.Expect(jj.GitPush("--change", "first", "--dry-run")).SetOutput([]byte(dryRunOutput))
+	c.Expect(jj.GitPush("--change", "first"))
+	defer c.Verify()
+
+	selected := []*jj.Commit{{ChangeId: "first"}}
+	op := NewModel(c, &jj.Commit{ChangeId: "current"}, selected, 0, 0)
+	tm := teatest.NewTestModel(t, test.NewShell(op))
+	tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("Are you sure you want to push?"))
+	})
+	tm.Type("y")
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+const pushedBookmarks = "feature-a;;false;false;1111;first\nfeature-a;origin;true;false;1111;first\nfeature-b;;false;false;2222;current\nfeature-b;origin;true;false;2222;current\n"
+
+func Test_PushStack(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.BookmarkList("current"))
+	c.Expect(jj.GitRemoteList())
+	c.Expect(jj.StackLog("current")).SetOutput([]byte("first;firstfull;1111;1111full;feature-a;first\ncurrent;currentfull;2222;2222full;feature-b;second\n"))
+	c.Expect(jj.BookmarkListRefs()).SetOutput([]byte(pushedBookmarks))
+	c.Expect(jj.GitPush("--bookmark", "feature-a", "--bookmark", "feature-b", "--dry-run")).SetOutput([]byte(dryRunOutput))
+	c.Expect(jj.GitPush("--bookmark", "feature-a", "--bookmark", "feature-b"))
+	defer c.Verify()
+
+	op := NewModel(c, &jj.Commit{ChangeId: "current"}, nil, 0, 0)
+	tm := teatest.NewTestModel(t, test.NewShell(op))
+	tm.Type("s")
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("2 revisions"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("Are you sure you want to push?"))
+	})
+	tm.Type("y")
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func Test_PushStackAllowsNewBookmarks(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.BookmarkList("current"))
+	c.Expect(jj.GitRemoteList())
+	c.Expect(jj.StackLog("current")).SetOutput([]byte("first;firstfull;1111;1111full;feature-a;first\ncurrent;currentfull;2222;2222full;feature-b;second\n"))
+	// feature-b was created earlier but never pushed
+	c.Expect(jj.BookmarkListRefs()).SetOutput([]byte("feature-a;;false;false;1111;first\nfeature-a;origin;true;false;1111;first\nfeature-b;;false;false;2222;current\n"))
+	c.Expect(jj.GitPush("--bookmark", "feature-a", "--bookmark", "feature-b", "--allow-new", "--dry-run")).SetOutput([]byte(dryRunOutput))
+	c.Expect(jj.GitPush("--bookmark", "feature-a", "--bookmark", "feature-b", "--allow-new"))
+	defer c.Verify()
+
+	op := NewModel(c, &jj.Commit{ChangeId: "current"}, nil, 0, 0)
+	tm := teatest.NewTestModel(t, test.NewShell(op))
+	tm.Type("s")
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("2 revisions"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("Are you sure you want to push?"))
+	})
+	tm.Type("y")
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func Test_StackCreatesMissingBookmarks(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.BookmarkList("current"))
+	c.Expect(jj.GitRemoteList())
+	c.Expect(jj.StackLog("current")).SetOutput([]byte("first;firstfull;1111;1111full;;first\ncurrent;currentfull;2222;2222full;feature-b;second\n"))
+	c.Expect(jj.BookmarkListRefs())
+	c.Expect(jj.BookmarkCreate("first", "push-first"))
+	defer c.Verify()
+
+	op := NewModel(c, &jj.Commit{ChangeId: "current"}, nil, 0, 0)
+	tm := teatest.NewTestModel(t, test.NewShell(op))
+	tm.Type("s")
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("1 without a bookmark"))
+	})
+	tm.Type("c")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEsc})
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("Showing all"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEsc})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
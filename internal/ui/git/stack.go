@@ -0,0 +1,208 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+)
+
+type updateStackMsg struct {
+	entries []jj.StackEntry
+	pushed  map[string]bool
+	err     error
+	output  string
+}
+
+type pushStackMsg struct {
+	args jj.CommandArgs
+}
+
+type closeStackMsg struct{}
+
+// stackModel lists the revisions between trunk() and the selected revision in ancestor order
+// so that the whole chain of bookmarks can be pushed at once
+type stackModel struct {
+	context  context.AppContext
+	keymap   config.KeyMappings[key.Binding]
+	revision string
+	entries  []jj.StackEntry
+	cursor   int
+	pushed   map[string]bool
+	error    string
+}
+
+func newStackModel(c context.AppContext, revision string) *stackModel {
+	return &stackModel{
+		context:  c,
+		keymap:   c.KeyMap(),
+		revision: revision,
+	}
+}
+
+func (s *stackModel) load() tea.Msg {
+	output, err := s.context.RunCommandImmediate(jj.StackLog(s.revision))
+	if err != nil {
+		return updateStackMsg{err: err, output: string(output)}
+	}
+	refsOutput, err := s.context.RunCommandImmediate(jj.BookmarkListRefs())
+	if err != nil {
+		return updateStackMsg{err: err, output: string(refsOutput)}
+	}
+	// bookmarks tracking a remote bookmark were pushed before, the rest are new to the remotes
+	pushed := make(map[string]bool)
+	for _, ref := range jj.ParseBookmarkRefs(string(refsOutput)) {
+		if !ref.IsLocal() && ref.Remote != "git" && ref.Tracked {
+			pushed[ref.Name] = true
+		}
+	}
+	return updateStackMsg{entries: jj.ParseStackOutput(string(output)), pushed: pushed}
+}
+
+func (s *stackModel) Update(msg tea.Msg) (*stackModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case updateStackMsg:
+		s.error = ""
+		if msg.err != nil {
+			s.error = strings.TrimSpace(msg.output)
+			return s, nil
+		}
+		s.entries = msg.entries
+		s.pushed = msg.pushed
+		s.cursor = min(s.cursor, max(len(s.entries)-1, 0))
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, s.keymap.Cancel):
+			return s, func() tea.Msg { return closeStackMsg{} }
+		case key.Matches(msg, s.keymap.Up):
+			if s.cursor > 0 {
+				s.cursor--
+			}
+		case key.Matches(msg, s.keymap.Down):
+			if s.cursor < len(s.entries)-1 {
+				s.cursor++
+			}
+		case key.Matches(msg, s.keymap.Git.CreateBookmarks):
+			return s, s.createMissing()
+		case key.Matches(msg, s.keymap.Apply):
+			return s, s.push()
+		}
+	}
+	return s, nil
+}
+
+func (s *stackModel) bookmarkName(index int) string {
+	entry := s.entries[index]
+	replacer := strings.NewReplacer(
+		"{change_id}", entry.ChangeId,
+		"{commit_id}", entry.CommitId,
+		"{index}", strconv.Itoa(index+1),
+	)
+	return replacer.Replace(config.Current.Git.StackBookmarkPattern)
+}
+
+func (s *stackModel) missing() []int {
+	var indices []int
+	for i, entry := range s.entries {
+		if len(entry.Bookmarks) == 0 {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+func (s *stackModel) createMissing() tea.Cmd {
+	missing := s.missing()
+	if len(missing) == 0 {
+		return nil
+	}
+	// commands are chained so that bookmarks are created one after another and the stack is reloaded at the end
+	var cmd tea.Cmd
+	for i := len(missing) - 1; i >= 0; i-- {
+		index := missing[i]
+		args := jj.BookmarkCreate(s.entries[index].ChangeId, s.bookmarkName(index))
+		if cmd == nil {
+			cmd = s.context.RunCommand(args, common.Refresh, s.load)
+		} else {
+			cmd = s.context.RunCommand(args, cmd)
+		}
+	}
+	return cmd
+}
+
+func (s *stackModel) push() tea.Cmd {
+	var flags []string
+	allowNew := false
+	for _, entry := range s.entries {
+		for _, b := range entry.Bookmarks {
+			flags = append(flags, "--bookmark", b)
+			allowNew = allowNew || !s.pushed[b]
+		}
+	}
+	if len(flags) == 0 {
+		return nil
+	}
+	if allowNew {
+		flags = append(flags, "--allow-new")
+	}
+	return func() tea.Msg {
+		return pushStackMsg{args: jj.GitPush(flags...)}
+	}
+}
+
+func (s *stackModel) View(width int, height int) string {
+	title := filterValueStyle.PaddingLeft(2).Render(fmt.Sprintf("Stack trunk()..%s", s.revision))
+	if s.error != "" {
+		return lipgloss.JoinVertical(0, title, "", common.DefaultPalette.StatusError.PaddingLeft(2).Render(s.error))
+	}
+	if s.entries == nil {
+		return lipgloss.JoinVertical(0, title, "", "  loading")
+	}
+
+	start := max(0, s.cursor-height+1)
+	end := min(len(s.entries), start+height)
+	var lines []string
+	for i := start; i < end; i++ {
+		entry := s.entries[i]
+		style := common.DefaultPalette.Normal
+		if i == s.cursor {
+			style = style.Bold(true).Background(common.IntenseBlack)
+		}
+		var bookmarks string
+		if len(entry.Bookmarks) == 0 {
+			bookmarks = common.DefaultPalette.Deleted.Render("missing") + common.DefaultPalette.Dimmed.Render(" → "+s.bookmarkName(i))
+		} else {
+			bookmarks = common.DefaultPalette.Added.Render(strings.Join(entry.Bookmarks, ", "))
+		}
+		line := lipgloss.JoinHorizontal(0,
+			"  ",
+			common.DefaultPalette.ChangeId.Render(entry.ChangeId),
+			" ",
+			bookmarks,
+			" ",
+			entry.Description,
+		)
+		lines = append(lines, style.MaxWidth(width).Render(line))
+	}
+
+	summary := fmt.Sprintf("%d revisions", len(s.entries))
+	if missing := len(s.missing()); missing > 0 {
+		summary += fmt.Sprintf(", %d without a bookmark", missing)
+	}
+	return lipgloss.JoinVertical(0, title, filterStyle.Render(summary), "", lipgloss.JoinVertical(0, lines...))
+}
+
+func (s *stackModel) helpView() string {
+	return " " + lipgloss.JoinHorizontal(0,
+		renderKey(s.keymap.Apply),
+		renderKey(s.keymap.Git.CreateBookmarks),
+		renderKey(s.keymap.Cancel),
+	)
+}
@@ -99,6 +99,8 @@ func (h *Model) View() string {
 		printHelp(h.keyMap.Describe),
 		printHelp(h.keyMap.Edit),
 		printHelp(h.keyMap.Diff),
+		printHelp(h.keyMap.Compare),
+		printHelp(h.keyMap.RangeDiff),
 		printHelp(h.keyMap.Diffedit),
 		printHelp(h.keyMap.Split),
 		printHelp(h.keyMap.Squash),
@@ -114,6 +116,41 @@ func (h *Model) View() string {
 		printHelp(h.keyMap.Preview.ScrollDown),
 		printHelp(h.keyMap.Preview.HalfPageDown),
 		printHelp(h.keyMap.Preview.HalfPageUp),
+		printHelp(h.keyMap.Preview.SideBySide),
+		printHelp(h.keyMap.Preview.Format),
+		printHelp(h.keyMap.Preview.Whitespace),
+		printHelp(h.keyMap.Preview.Context),
+		printHelp(h.keyMap.Preview.Position),
+		printHelp(h.keyMap.Preview.Grow),
+		printHelp(h.keyMap.Preview.Shrink),
+		printHelp(h.keyMap.Preview.Maximize),
+		printHelp(h.keyMap.Preview.Search),
+		printHelp(h.keyMap.Preview.NextMatch),
+		printHelp(h.keyMap.Preview.PrevMatch),
+		printHelp(h.keyMap.Preview.ScrollLeft),
+		printHelp(h.keyMap.Preview.ScrollRight),
+		"",
+		printMode(h.keyMap.Diff, "Diff"),
+		printHelp(h.keyMap.DiffView.NextFile),
+		printHelp(h.keyMap.DiffView.PrevFile),
+		printHelp(h.keyMap.DiffView.NextHunk),
+		printHelp(h.keyMap.DiffView.PrevHunk),
+		printHelp(h.keyMap.DiffView.Search),
+		printHelp(h.keyMap.DiffView.NextMatch),
+		printHelp(h.keyMap.DiffView.PrevMatch),
+		printHelp(h.keyMap.DiffView.ToggleSideBySide),
+		printHelp(h.keyMap.DiffView.Format),
+		printHelp(h.keyMap.DiffView.Whitespace),
+		printHelp(h.keyMap.DiffView.Context),
+		printHelp(h.keyMap.DiffView.Pager),
+		"",
+		printMode(h.keyMap.Evolog, "Evolog"),
+		printHelp(h.keyMap.EvologView.Restore),
+		printHelp(h.keyMap.EvologView.Interdiff),
+		"",
+		printMode(h.keyMap.Tag.Mode, "Tags"),
+		printHelp(h.keyMap.Tag.Create),
+		printHelp(h.keyMap.Tag.Delete),
 	)
 
 	rightView := lipgloss.JoinVertical(lipgloss.Left,
@@ -122,12 +159,18 @@ func (h *Model) View() string {
 		printHelp(h.keyMap.Details.ToggleSelect),
 		printHelp(h.keyMap.Details.Restore),
 		printHelp(h.keyMap.Details.Split),
+		printHelp(h.keyMap.Details.Squash),
 		printHelp(h.keyMap.Details.Diff),
 		printHelp(h.keyMap.Details.RevisionsChangingFile),
+		printHelp(h.keyMap.Details.Annotate),
+		printHelp(h.keyMap.Details.SelectHunks),
 		"",
 		printMode(h.keyMap.Git.Mode, "Git"),
 		printHelp(h.keyMap.Git.Push),
 		printHelp(h.keyMap.Git.Fetch),
+		printHelp(h.keyMap.Git.Remote),
+		printHelp(h.keyMap.Git.Stack),
+		printHelp(h.keyMap.Git.CreateBookmarks),
 		"",
 		printMode(h.keyMap.Bookmark.Mode, "Bookmarks"),
 		printHelp(h.keyMap.Bookmark.Move),
@@ -135,6 +178,9 @@ func (h *Model) View() string {
 		printHelp(h.keyMap.Bookmark.Untrack),
 		printHelp(h.keyMap.Bookmark.Track),
 		printHelp(h.keyMap.Bookmark.Forget),
+		printHelp(h.keyMap.Bookmark.Rename),
+		printHelp(h.keyMap.Bookmark.Resolve),
+		printHelp(h.keyMap.Bookmark.Overview),
 		"",
 		printMode(h.keyMap.Rebase.Mode, "Rebase"),
 		printHelp(h.keyMap.Rebase.Revision),
@@ -147,7 +193,13 @@ func (h *Model) View() string {
 		"",
 		printMode(h.keyMap.OpLog.Mode, "Oplog"),
 		printHelp(h.keyMap.Diff),
+		printHelp(h.keyMap.ToggleSelect),
+		printHelp(h.keyMap.Compare),
 		printHelp(h.keyMap.OpLog.Restore),
+		printHelp(h.keyMap.OpLog.RangeDiff),
+		printHelp(h.keyMap.OpLog.Undo),
+		printHelp(h.keyMap.OpLog.Abandon),
+		printHelp(h.keyMap.OpLog.Filter),
 	)
 
 	content := lipgloss.JoinHorizontal(lipgloss.Left, leftView, "  ", rightView)
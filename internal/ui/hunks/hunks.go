@@ -0,0 +1,399 @@
+package hunks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+)
+
+type Mode int
+
+const (
+	// Split keeps the selected changes in the revision and moves the rest to a new revision
+	Split Mode = iota
+	// Squash moves the selected changes into the parent
+	Squash
+	// Restore discards the selected changes
+	Restore
+)
+
+// contextLines is the number of unchanged lines shown around the changes, changes closer than twice this are grouped in the same hunk
+const contextLines = 3
+
+// StartMsg asks to open the hunk selector for the files of the revision
+type StartMsg struct {
+	Revision string
+	Files    []string
+	Mode     Mode
+}
+
+func Start(revision string, files []string, mode Mode) tea.Cmd {
+	return func() tea.Msg {
+		return StartMsg{Revision: revision, Files: files, Mode: mode}
+	}
+}
+
+type updateFilesMsg struct {
+	files []jj.DiffFile
+}
+
+// hunk is a group of changed lines, start and end are indices of the first and the last changed lines in the diff hunk
+type hunk struct {
+	start int
+	end   int
+}
+
+type file struct {
+	diff     jj.DiffFile
+	hunks    []hunk
+	selected map[int]bool
+}
+
+// row is a rendered line of the selector, hunk and line are -1 for file and hunk headers
+type row struct {
+	file       int
+	hunk       int
+	line       int
+	selectable bool
+}
+
+type Model struct {
+	context     context.AppContext
+	keymap      config.KeyMappings[key.Binding]
+	revision    string
+	paths       []string
+	mode        Mode
+	files       []file
+	unsupported []string
+	rows        []row
+	loaded      bool
+	err         string
+	cursor      int
+	top         int
+	width       int
+	height      int
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.load
+}
+
+func (m *Model) load() tea.Msg {
+	output, err := m.context.RunCommandImmediate(jj.SelectionDiff(m.revision, m.paths))
+	if err != nil {
+		return common.CommandCompletedMsg{Output: string(output), Err: err}
+	}
+	return updateFilesMsg{files: jj.ParseRawGitDiff(string(output))}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case updateFilesMsg:
+		m.setFiles(msg.files)
+	case common.CommandCompletedMsg:
+		if msg.Err != nil {
+			m.err = strings.TrimSpace(msg.Output)
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keymap.Cancel):
+			return m, common.Close
+		case key.Matches(msg, m.keymap.Up):
+			m.moveTo(m.findRow(m.cursor-1, -1, func(r row) bool { return r.selectable }))
+		case key.Matches(msg, m.keymap.Down):
+			m.moveTo(m.findRow(m.cursor+1, 1, func(r row) bool { return r.selectable }))
+		case key.Matches(msg, m.keymap.DiffView.PrevHunk):
+			m.moveTo(m.findRow(m.cursor-1, -1, func(r row) bool { return r.line == -1 }))
+		case key.Matches(msg, m.keymap.DiffView.NextHunk):
+			m.moveTo(m.findRow(m.cursor+1, 1, func(r row) bool { return r.line == -1 }))
+		case key.Matches(msg, m.keymap.DiffView.PrevFile):
+			m.moveTo(m.findRow(m.cursor-1, -1, func(r row) bool { return r.hunk == -1 }))
+		case key.Matches(msg, m.keymap.DiffView.NextFile):
+			m.moveTo(m.findRow(m.cursor+1, 1, func(r row) bool { return r.hunk == -1 }))
+		case key.Matches(msg, m.keymap.ToggleSelect):
+			m.toggle()
+		case key.Matches(msg, m.keymap.Apply):
+			return m, m.apply()
+		}
+	}
+	return m, nil
+}
+
+// setFiles groups the changes of the files into hunks
+func (m *Model) setFiles(diffs []jj.DiffFile) {
+	m.loaded = true
+	m.files = nil
+	m.unsupported = nil
+	for _, d := range diffs {
+		if !jj.CanSelectChanges(d) {
+			m.unsupported = append(m.unsupported, d.Name)
+			continue
+		}
+		f := file{diff: d, selected: make(map[int]bool)}
+		for i, line := range d.Hunks[0].Lines {
+			if line.Kind == jj.DiffContext {
+				continue
+			}
+			if n := len(f.hunks); n > 0 && i-f.hunks[n-1].end <= 2*contextLines+1 {
+				f.hunks[n-1].end = i
+			} else {
+				f.hunks = append(f.hunks, hunk{start: i, end: i})
+			}
+		}
+		m.files = append(m.files, f)
+	}
+
+	m.rows = nil
+	for fi, f := range m.files {
+		m.rows = append(m.rows, row{file: fi, hunk: -1, line: -1, selectable: true})
+		lines := f.diff.Hunks[0].Lines
+		for hi, h := range f.hunks {
+			m.rows = append(m.rows, row{file: fi, hunk: hi, line: -1, selectable: true})
+			for i := max(h.start-contextLines, 0); i <= min(h.end+contextLines, len(lines)-1); i++ {
+				m.rows = append(m.rows, row{file: fi, hunk: hi, line: i, selectable: lines[i].Kind != jj.DiffContext})
+			}
+		}
+	}
+	m.cursor, m.top = 0, 0
+}
+
+func (m *Model) findRow(from int, step int, matches func(r row) bool) int {
+	for i := from; i >= 0 && i < len(m.rows); i += step {
+		if matches(m.rows[i]) {
+			return i
+		}
+	}
+	return m.cursor
+}
+
+func (m *Model) pageSize() int {
+	return max(m.height-3, 1)
+}
+
+func (m *Model) moveTo(cursor int) {
+	m.cursor = cursor
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+m.pageSize() {
+		m.top = m.cursor - m.pageSize() + 1
+	}
+}
+
+// changedLines returns the indices of the changed lines the row covers
+func (m *Model) changedLines(r row) []int {
+	f := m.files[r.file]
+	if r.line != -1 {
+		return []int{r.line}
+	}
+	var lines []int
+	for hi, h := range f.hunks {
+		if r.hunk != -1 && r.hunk != hi {
+			continue
+		}
+		for i := h.start; i <= h.end; i++ {
+			if f.diff.Hunks[0].Lines[i].Kind != jj.DiffContext {
+				lines = append(lines, i)
+			}
+		}
+	}
+	return lines
+}
+
+// state returns how many of the changed lines the row covers are selected, and how many there are
+func (m *Model) state(r row) (int, int) {
+	lines := m.changedLines(r)
+	selected := 0
+	for _, i := range lines {
+		if m.files[r.file].selected[i] {
+			selected++
+		}
+	}
+	return selected, len(lines)
+}
+
+// toggle selects all changed lines the row at the cursor covers, or unselects them if they are all selected
+func (m *Model) toggle() {
+	if m.cursor >= len(m.rows) {
+		return
+	}
+	r := m.rows[m.cursor]
+	selected, total := m.state(r)
+	for _, i := range m.changedLines(r) {
+		m.files[r.file].selected[i] = selected < total
+	}
+}
+
+// fileStates returns the contents of the files with selected changes after the command is applied
+func (m *Model) fileStates() ([]jj.FileState, []string) {
+	var states []jj.FileState
+	var paths []string
+	for _, f := range m.files {
+		selected := false
+		for _, s := range f.selected {
+			selected = selected || s
+		}
+		if !selected {
+			continue
+		}
+		// restoring applies the changes that are not selected
+		states = append(states, jj.SelectedFileState(f.diff, func(line int) bool { return f.selected[line] != (m.mode == Restore) }))
+		paths = append(paths, f.diff.Name)
+	}
+	return states, paths
+}
+
+func (m *Model) apply() tea.Cmd {
+	states, paths := m.fileStates()
+	if len(states) == 0 {
+		return func() tea.Msg { return common.CommandCompletedMsg{Err: errors.New("no changes are selected")} }
+	}
+	selection, err := jj.WriteSelection(states)
+	if err != nil {
+		return func() tea.Msg { return common.CommandCompletedMsg{Err: err} }
+	}
+	program, err := os.Executable()
+	if err != nil {
+		return func() tea.Msg { return common.CommandCompletedMsg{Err: err} }
+	}
+	// the selection holds the contents of the files, so it's removed whether the command succeeds or not
+	cleanup := func() {
+		_ = os.Remove(selection)
+	}
+	var run tea.Cmd
+	switch m.mode {
+	case Split:
+		run = m.context.RunInteractiveCommandWithCleanup(jj.SplitSelection(m.revision, paths, program, selection), cleanup, common.Refresh)
+	case Squash:
+		run = m.context.RunInteractiveCommandWithCleanup(jj.SquashSelection(m.revision, paths, program, selection), cleanup, common.Refresh)
+	case Restore:
+		// continuations of non-interactive commands run even when the command fails
+		run = m.context.RunCommand(jj.RestoreSelection(m.revision, paths, program, selection), func() tea.Msg {
+			cleanup()
+			return nil
+		}, common.Refresh)
+	}
+	// close the selector and the details view first
+	return tea.Sequence(common.Close, common.Close, run)
+}
+
+func (m *Model) title() string {
+	switch m.mode {
+	case Squash:
+		return "select the changes to move into the parent of"
+	case Restore:
+		return "select the changes to restore in"
+	default:
+		return "select the changes to keep in"
+	}
+}
+
+func (m *Model) View() string {
+	title := lipgloss.JoinHorizontal(0,
+		common.DefaultPalette.Normal.Bold(true).Render(m.title()),
+		" ",
+		common.DefaultPalette.ChangeId.Render(m.revision),
+	)
+	note := ""
+	if len(m.unsupported) > 0 {
+		note = common.DefaultPalette.Dimmed.Render(fmt.Sprintf("changes of %s can't be selected and are left out", strings.Join(m.unsupported, ", ")))
+	}
+	var content string
+	switch {
+	case m.err != "":
+		content = common.DefaultPalette.StatusError.Render(m.err)
+	case !m.loaded:
+		content = "loading"
+	case len(m.rows) == 0:
+		content = common.DefaultPalette.Dimmed.Render("there are no changes to select")
+	default:
+		end := min(len(m.rows), m.top+m.pageSize())
+		var rendered []string
+		for i := m.top; i < end; i++ {
+			style := lipgloss.NewStyle().MaxWidth(m.width)
+			if i == m.cursor {
+				style = style.Bold(true).Background(common.IntenseBlack)
+			}
+			rendered = append(rendered, style.Render(m.renderRow(m.rows[i])))
+		}
+		content = lipgloss.JoinVertical(0, rendered...)
+	}
+	help := lipgloss.JoinHorizontal(0,
+		renderKey(m.keymap.ToggleSelect),
+		renderKey(m.keymap.DiffView.NextHunk),
+		renderKey(m.keymap.DiffView.PrevHunk),
+		renderKey(m.keymap.DiffView.NextFile),
+		renderKey(m.keymap.DiffView.PrevFile),
+		renderKey(m.keymap.Apply),
+		renderKey(m.keymap.Cancel),
+	)
+	content = lipgloss.Place(m.width, max(m.height-3, 0), 0, 0, content)
+	return lipgloss.JoinVertical(0, title, note, content, help)
+}
+
+func (m *Model) renderRow(r row) string {
+	f := m.files[r.file]
+	if r.line == -1 {
+		selected, total := m.state(r)
+		if r.hunk == -1 {
+			return checkbox(selected, total) + " " + common.DefaultPalette.Normal.Bold(true).Render(f.diff.Name)
+		}
+		return "  " + checkbox(selected, total) + " " + common.DefaultPalette.Dimmed.Render(fmt.Sprintf("hunk %d/%d", r.hunk+1, len(f.hunks)))
+	}
+	line := f.diff.Hunks[0].Lines[r.line]
+	content := strings.ReplaceAll(line.Content, "\t", "    ")
+	switch line.Kind {
+	case jj.DiffAdded:
+		return "    " + checkbox(boolToInt(f.selected[r.line]), 1) + " " + common.DefaultPalette.Added.Render("+"+content)
+	case jj.DiffRemoved:
+		return "    " + checkbox(boolToInt(f.selected[r.line]), 1) + " " + common.DefaultPalette.Deleted.Render("-"+content)
+	default:
+		return "        " + common.DefaultPalette.Dimmed.Render(" "+content)
+	}
+}
+
+func checkbox(selected int, total int) string {
+	switch {
+	case selected == 0:
+		return "[ ]"
+	case selected < total:
+		return "[~]"
+	default:
+		return "[x]"
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func renderKey(k key.Binding) string {
+	return lipgloss.JoinHorizontal(0, common.DefaultPalette.ChangeId.Render(k.Help().Key, ""), common.DefaultPalette.Dimmed.Render(k.Help().Desc, ""))
+}
+
+func New(context context.AppContext, revision string, paths []string, mode Mode, width int, height int) *Model {
+	return &Model{
+		context:  context,
+		keymap:   context.KeyMap(),
+		revision: revision,
+		paths:    paths,
+		mode:     mode,
+		width:    width,
+		height:   height,
+	}
+}
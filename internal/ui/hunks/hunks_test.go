@@ -0,0 +1,91 @@
+package hunks
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func fullContextDiff() string {
+	var b strings.Builder
+	b.WriteString("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,20 +1,20 @@\n")
+	for i := 1; i <= 20; i++ {
+		switch i {
+		case 2, 18:
+			b.WriteString("-old\n+new\n")
+		default:
+			b.WriteString(" line\n")
+		}
+	}
+	return b.String()
+}
+
+func load(t *testing.T, mode Mode) (*test.TestContext, *Model) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.SelectionDiff("abc", []string{"main.go"})).SetOutput([]byte(fullContextDiff()))
+	m := New(c, "abc", []string{"main.go"}, mode, 80, 40)
+	m.Update(m.Init()())
+	return c, m
+}
+
+func press(m *Model, msgs ...tea.KeyMsg) {
+	for _, msg := range msgs {
+		m.Update(msg)
+	}
+}
+
+var (
+	down     = tea.KeyMsg{Type: tea.KeyDown}
+	toggle   = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	nextHunk = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")}
+)
+
+func TestChangesAreGroupedInHunks(t *testing.T) {
+	c, m := load(t, Split)
+	defer c.Verify()
+
+	assert.Len(t, m.files, 1)
+	assert.Len(t, m.files[0].hunks, 2)
+	assert.Contains(t, m.View(), "hunk 2/2")
+}
+
+func TestSelectingAHunk(t *testing.T) {
+	c, m := load(t, Split)
+	defer c.Verify()
+
+	press(m, nextHunk, toggle)
+	states, paths := m.fileStates()
+	assert.Equal(t, []string{"main.go"}, paths)
+	content := strings.Split(states[0].Content, "\n")
+	assert.Equal(t, "new", content[1])
+	assert.Equal(t, "old", content[17])
+	assert.Contains(t, m.View(), "[~] main.go")
+}
+
+func TestSelectingALine(t *testing.T) {
+	c, m := load(t, Restore)
+	defer c.Verify()
+
+	// the first changed line is the removal of the first hunk
+	press(m, nextHunk, down, toggle)
+	states, _ := m.fileStates()
+	content := strings.Split(states[0].Content, "\n")
+	// restoring the removal keeps the old line next to the added one
+	assert.Equal(t, []string{"line", "old", "new"}, content[:3])
+	assert.Equal(t, "new", content[18])
+}
+
+func TestNothingSelected(t *testing.T) {
+	c, m := load(t, Squash)
+	defer c.Verify()
+
+	states, _ := m.fileStates()
+	assert.Empty(t, states)
+	msg := m.apply()().(common.CommandCompletedMsg)
+	assert.EqualError(t, msg.Err, "no changes are selected")
+}
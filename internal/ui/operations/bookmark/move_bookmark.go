@@ -0,0 +1,107 @@
+package bookmark
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/operations"
+)
+
+// StartMoveMsg asks the revisions view to start moving the given bookmark with the cursor
+type StartMoveMsg struct {
+	Bookmark string
+}
+
+func StartMove(bookmark string) tea.Cmd {
+	return func() tea.Msg {
+		return StartMoveMsg{Bookmark: bookmark}
+	}
+}
+
+// MoveMsg carries the move command once it's known whether the bookmark moves backwards
+type MoveMsg struct {
+	Destination string
+	Args        jj.CommandArgs
+}
+
+type MoveBookmarkOperation struct {
+	context  context.AppContext
+	keyMap   config.KeyMappings[key.Binding]
+	Bookmark string
+	Current  *jj.Commit
+}
+
+func (m *MoveBookmarkOperation) HandleKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keyMap.Apply):
+		if m.Current == nil {
+			return nil
+		}
+		return m.move(m.Current.GetChangeId())
+	case key.Matches(msg, m.keyMap.Cancel):
+		return common.Close
+	}
+	return nil
+}
+
+// move adds --allow-backwards when the destination is not a descendant of the bookmark's current target
+func (m *MoveBookmarkOperation) move(destination string) tea.Cmd {
+	return func() tea.Msg {
+		revset := fmt.Sprintf("%s & bookmarks(exact:%q)::", destination, m.Bookmark)
+		output, err := m.context.RunCommandImmediate(jj.LogCommitIds(revset))
+		var flags []string
+		if err == nil && strings.TrimSpace(string(output)) == "" {
+			flags = append(flags, "--allow-backwards")
+		}
+		return MoveMsg{Destination: destination, Args: jj.BookmarkMove(destination, m.Bookmark, flags...)}
+	}
+}
+
+func (m *MoveBookmarkOperation) SetSelectedRevision(commit *jj.Commit) {
+	m.Current = commit
+}
+
+func (m *MoveBookmarkOperation) Render() string {
+	return lipgloss.JoinHorizontal(0,
+		common.DropStyle.Render("<< move >>"),
+		" ",
+		common.DefaultPalette.Added.Render(m.Bookmark),
+		" ",
+	)
+}
+
+func (m *MoveBookmarkOperation) RenderPosition() operations.RenderPosition {
+	return operations.RenderBeforeChangeId
+}
+
+func (m *MoveBookmarkOperation) Name() string {
+	return "move"
+}
+
+func (m *MoveBookmarkOperation) ShortHelp() []key.Binding {
+	return []key.Binding{
+		m.keyMap.Up,
+		m.keyMap.Down,
+		m.keyMap.Apply,
+		m.keyMap.Cancel,
+	}
+}
+
+func (m *MoveBookmarkOperation) FullHelp() [][]key.Binding {
+	return [][]key.Binding{m.ShortHelp()}
+}
+
+func NewMoveBookmarkOperation(context context.AppContext, bookmark string) *MoveBookmarkOperation {
+	return &MoveBookmarkOperation{
+		context:  context,
+		keyMap:   context.KeyMap(),
+		Bookmark: bookmark,
+	}
+}
@@ -0,0 +1,32 @@
+package bookmark
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveBookmark_Forwards(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.LogCommitIds(`target & bookmarks(exact:"main")::`)).SetOutput([]byte("1234abcd"))
+	defer c.Verify()
+
+	op := NewMoveBookmarkOperation(c, "main")
+	op.SetSelectedRevision(&jj.Commit{ChangeId: "target"})
+	cmd := op.HandleKey(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, MoveMsg{Destination: "target", Args: jj.BookmarkMove("target", "main")}, cmd())
+}
+
+func TestMoveBookmark_AllowsBackwards(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.LogCommitIds(`target & bookmarks(exact:"main")::`))
+	defer c.Verify()
+
+	op := NewMoveBookmarkOperation(c, "main")
+	op.SetSelectedRevision(&jj.Commit{ChangeId: "target"})
+	cmd := op.HandleKey(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, MoveMsg{Destination: "target", Args: jj.BookmarkMove("target", "main", "--allow-backwards")}, cmd())
+}
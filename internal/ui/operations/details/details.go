@@ -2,7 +2,6 @@ package details
 
 import (
 	"fmt"
-	"github.com/idursun/jjui/internal/ui/revset"
 	"io"
 	"path"
 	"strings"
@@ -10,6 +9,8 @@ import (
 	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/hunks"
+	"github.com/idursun/jjui/internal/ui/operations/filehistory"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -107,6 +108,7 @@ func (i itemDelegate) showHint() bool {
 
 type Model struct {
 	revision     string
+	commitId     string
 	files        list.Model
 	height       int
 	confirmation tea.Model
@@ -116,7 +118,18 @@ type Model struct {
 
 type updateCommitStatusMsg []string
 
-func New(context context.AppContext, revision string) tea.Model {
+// ShowMsg asks the revisions view to select the given revision and open its details
+type ShowMsg struct {
+	Revision string
+}
+
+func Show(revision string) tea.Cmd {
+	return func() tea.Msg {
+		return ShowMsg{Revision: revision}
+	}
+}
+
+func New(context context.AppContext, revision string, commitId string) tea.Model {
 	keyMap := context.KeyMap()
 	l := list.New(nil, itemDelegate{}, 0, 0)
 	l.SetFilteringEnabled(false)
@@ -128,6 +141,7 @@ func New(context context.AppContext, revision string) tea.Model {
 	l.KeyMap.CursorDown = keyMap.Down
 	return Model{
 		revision: revision,
+		commitId: commitId,
 		files:    l,
 		context:  context,
 		keyMap:   context.KeyMap(),
@@ -151,10 +165,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, common.Close
 		case key.Matches(msg, m.keyMap.Details.Diff):
 			v := m.files.SelectedItem().(item).fileName
-			return m, func() tea.Msg {
-				output, _ := m.context.RunCommandImmediate(jj.Diff(m.revision, v))
-				return common.ShowDiffMsg(output)
-			}
+			return m, common.ShowRevisionDiff(m.revision, v)
 		case key.Matches(msg, m.keyMap.Details.Split):
 			selectedFiles, isVirtuallySelected := m.getSelectedFiles()
 			m.files.SetDelegate(itemDelegate{
@@ -165,6 +176,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			model := confirmation.New("Are you sure you want to split the selected files?")
 
 			model.AddOption("Yes", tea.Batch(common.Close, m.context.RunInteractiveCommand(jj.Split(m.revision, selectedFiles), common.Refresh)), key.NewBinding(key.WithKeys("y")))
+			model.AddOption("Select hunks", tea.Batch(confirmation.Close, hunks.Start(m.revision, selectedFiles, hunks.Split)), m.keyMap.Details.SelectHunks)
 			model.AddOption("No", confirmation.Close, key.NewBinding(key.WithKeys("n", "esc")))
 			m.confirmation = &model
 			return m, m.confirmation.Init()
@@ -177,6 +189,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 			model := confirmation.New("Are you sure you want to restore the selected files?")
 			model.AddOption("Yes", m.context.RunCommand(jj.Restore(m.revision, selectedFiles), common.Refresh, common.Close), key.NewBinding(key.WithKeys("y")))
+			model.AddOption("Select hunks", tea.Batch(confirmation.Close, hunks.Start(m.revision, selectedFiles, hunks.Restore)), m.keyMap.Details.SelectHunks)
+			model.AddOption("No", confirmation.Close, key.NewBinding(key.WithKeys("n", "esc")))
+			m.confirmation = &model
+			return m, m.confirmation.Init()
+		case key.Matches(msg, m.keyMap.Details.Squash):
+			selectedFiles, isVirtuallySelected := m.getSelectedFiles()
+			m.files.SetDelegate(itemDelegate{
+				isVirtuallySelected: isVirtuallySelected,
+				selectedHint:        "moves to the parent",
+				unselectedHint:      "stays as is",
+			})
+			model := confirmation.New("Are you sure you want to squash the selected files into the parent?")
+			model.AddOption("Yes", tea.Batch(common.Close, m.context.RunInteractiveCommand(jj.SquashFiles(m.revision, selectedFiles), common.Refresh)), key.NewBinding(key.WithKeys("y")))
+			model.AddOption("Select hunks", tea.Batch(confirmation.Close, hunks.Start(m.revision, selectedFiles, hunks.Squash)), m.keyMap.Details.SelectHunks)
 			model.AddOption("No", confirmation.Close, key.NewBinding(key.WithKeys("n", "esc")))
 			m.confirmation = &model
 			return m, m.confirmation.Init()
@@ -189,14 +215,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		case key.Matches(msg, m.keyMap.Details.RevisionsChangingFile):
 			if item, ok := m.files.SelectedItem().(item); ok {
-				return m, tea.Batch(common.Close, revset.UpdateRevSet(fmt.Sprintf("files(%s)", item.fileName)))
+				return m, filehistory.Start(item.fileName)
+			}
+		case key.Matches(msg, m.keyMap.Details.Annotate):
+			if item, ok := m.files.SelectedItem().(item); ok && item.status != Deleted {
+				return m, func() tea.Msg {
+					return common.ShowAnnotateMsg{Revision: m.revision, File: item.fileName}
+				}
 			}
 		default:
 			if len(m.files.Items()) > 0 {
 				var cmd tea.Cmd
 				m.files, cmd = m.files.Update(msg)
 				curItem := m.files.SelectedItem().(item)
-				return m, tea.Batch(cmd, m.context.SetSelectedItem(context.SelectedFile{ChangeId: m.revision, File: curItem.fileName}))
+				return m, tea.Batch(cmd, m.context.SetSelectedItem(context.SelectedFile{ChangeId: m.revision, CommitId: m.commitId, File: curItem.fileName}))
 			}
 		}
 	case confirmation.CloseMsg:
@@ -209,7 +241,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		items := m.parseFiles(msg)
 		var selectionChangedCmd tea.Cmd
 		if len(items) > 0 {
-			selectionChangedCmd = m.context.SetSelectedItem(context.SelectedFile{ChangeId: m.revision, File: items[0].(item).fileName})
+			selectionChangedCmd = m.context.SetSelectedItem(context.SelectedFile{ChangeId: m.revision, CommitId: m.commitId, File: items[0].(item).fileName})
 		}
 		return m, tea.Batch(selectionChangedCmd, m.files.SetItems(items))
 	case tea.WindowSizeMsg:
@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/idursun/jjui/internal/ui/context"
 	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/x/exp/teatest"
@@ -14,6 +16,7 @@ import (
 
 const (
 	Revision     = "ignored"
+	CommitId     = "ignored_commit"
 	StatusOutput = "M file.txt\nA newfile.txt\n"
 )
 
@@ -22,19 +25,29 @@ func TestModel_Init_ExecutesStatusCommand(t *testing.T) {
 	context.Expect(jj.Status(Revision)).SetOutput([]byte(StatusOutput))
 	defer context.Verify()
 
-	tm := teatest.NewTestModel(t, New(context, Revision))
+	tm := teatest.NewTestModel(t, New(context, Revision, CommitId))
 	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
 		return bytes.Contains(bts, []byte("file.txt"))
 	})
 }
 
+func TestModel_Update_SelectsFileOfCommit(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.Status(Revision)).SetOutput([]byte(StatusOutput))
+	defer c.Verify()
+
+	model := New(c, Revision, CommitId)
+	model.Update(model.Init()().(tea.BatchMsg)[0]())
+	assert.Equal(t, context.SelectedFile{ChangeId: Revision, CommitId: CommitId, File: "file.txt"}, c.SelectedItem())
+}
+
 func TestModel_Update_RestoresSelectedFiles(t *testing.T) {
 	c := test.NewTestContext(t)
 	c.Expect(jj.Status(Revision)).SetOutput([]byte(StatusOutput))
 	c.Expect(jj.Restore(Revision, []string{"file.txt"}))
 	defer c.Verify()
 
-	tm := teatest.NewTestModel(t, test.NewShell(New(c, Revision)))
+	tm := teatest.NewTestModel(t, test.NewShell(New(c, Revision, CommitId)))
 	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
 		return bytes.Contains(bts, []byte("file.txt"))
 	})
@@ -51,7 +64,7 @@ func TestModel_Update_SplitsSelectedFiles(t *testing.T) {
 	c.Expect(jj.Split(Revision, []string{"file.txt"}))
 	defer c.Verify()
 
-	tm := teatest.NewTestModel(t, test.NewShell(New(c, Revision)))
+	tm := teatest.NewTestModel(t, test.NewShell(New(c, Revision, CommitId)))
 	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
 		return bytes.Contains(bts, []byte("file.txt"))
 	})
@@ -68,7 +81,7 @@ func TestModel_Update_HandlesMovedFiles(t *testing.T) {
 	c.Expect(jj.Restore(Revision, []string{"internal/ui/file.go", "sub/newfile"}))
 	defer c.Verify()
 
-	tm := teatest.NewTestModel(t, test.NewShell(New(c, Revision)))
+	tm := teatest.NewTestModel(t, test.NewShell(New(c, Revision, CommitId)))
 	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
 		return bytes.Contains(bts, []byte("file.go"))
 	})
@@ -22,7 +22,10 @@ func (s Operation) ShortHelp() []key.Binding {
 		s.keyMap.Details.Diff,
 		s.keyMap.Details.ToggleSelect,
 		s.keyMap.Details.Split,
+		s.keyMap.Details.Squash,
 		s.keyMap.Details.Restore,
+		s.keyMap.Details.RevisionsChangingFile,
+		s.keyMap.Details.Annotate,
 	}
 }
 
@@ -50,7 +53,7 @@ func (s Operation) Name() string {
 
 func NewOperation(context context.AppContext, selected *jj.Commit) (operations.Operation, tea.Cmd) {
 	op := Operation{
-		Overlay: New(context, selected.GetChangeId()),
+		Overlay: New(context, selected.GetChangeId(), selected.CommitId),
 		keyMap:  context.KeyMap(),
 	}
 	return op, op.Overlay.Init()
@@ -2,6 +2,8 @@ package evolog
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -9,6 +11,7 @@ import (
 	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/confirmation"
 	"github.com/idursun/jjui/internal/ui/context"
 	"github.com/idursun/jjui/internal/ui/graph"
 	"github.com/idursun/jjui/internal/ui/operations"
@@ -24,18 +27,19 @@ type updateEvologMsg struct {
 }
 
 type Operation struct {
-	context   context.AppContext
-	revision  string
-	rows      []graph.Row
-	viewRange *viewRange
-	cursor    int
-	width     int
-	height    int
-	keyMap    config.KeyMappings[key.Binding]
+	context      context.AppContext
+	revision     string
+	rows         []graph.Row
+	viewRange    *viewRange
+	cursor       int
+	width        int
+	height       int
+	keyMap       config.KeyMappings[key.Binding]
+	confirmation *confirmation.Model
 }
 
 func (o Operation) ShortHelp() []key.Binding {
-	return []key.Binding{o.keyMap.Up, o.keyMap.Down, o.keyMap.Cancel, o.keyMap.Diff}
+	return []key.Binding{o.keyMap.Up, o.keyMap.Down, o.keyMap.Cancel, o.keyMap.Diff, o.keyMap.ToggleSelect, o.keyMap.Compare, o.keyMap.EvologView.Restore, o.keyMap.EvologView.Interdiff}
 }
 
 func (o Operation) FullHelp() [][]key.Binding {
@@ -47,16 +51,39 @@ func (o Operation) Update(msg tea.Msg) (operations.OperationWithOverlay, tea.Cmd
 	case updateEvologMsg:
 		o.rows = msg.rows
 		o.cursor = 0
+	case confirmation.CloseMsg:
+		o.confirmation = nil
+		return o, nil
 	case tea.KeyMsg:
+		if o.confirmation != nil {
+			_, cmd := o.confirmation.Update(msg)
+			return o, cmd
+		}
 		switch {
 		case key.Matches(msg, o.keyMap.Cancel):
 			return o, common.Close
 		case key.Matches(msg, o.keyMap.Diff):
-			return o, func() tea.Msg {
-				selectedCommitId := o.rows[o.cursor].Commit.CommitId
-				output, _ := o.context.RunCommandImmediate(jj.Diff(selectedCommitId, ""))
-				return common.ShowDiffMsg(output)
+			return o, common.ShowRevisionDiff(o.rows[o.cursor].Commit.CommitId, "")
+		case key.Matches(msg, o.keyMap.ToggleSelect):
+			if o.cursor < len(o.rows) {
+				o.rows[o.cursor].IsSelected = !o.rows[o.cursor].IsSelected
+			}
+		case key.Matches(msg, o.keyMap.Compare):
+			return o, o.compare()
+		case key.Matches(msg, o.keyMap.EvologView.Interdiff):
+			return o, o.interdiff()
+		case key.Matches(msg, o.keyMap.EvologView.Restore):
+			if o.cursor == 0 {
+				return o, func() tea.Msg {
+					return common.CommandCompletedMsg{Err: errors.New("this is already the current version")}
+				}
 			}
+			commitId := o.rows[o.cursor].Commit.CommitId
+			model := confirmation.New(fmt.Sprintf("Are you sure you want to restore %s to version %s?", o.revision, commitId))
+			model.AddOption("Yes", o.context.RunCommand(jj.RestoreFrom(commitId, o.revision), common.Refresh, common.Close), key.NewBinding(key.WithKeys("y")))
+			model.AddOption("No", confirmation.Close, key.NewBinding(key.WithKeys("n", "esc")))
+			o.confirmation = &model
+			return o, o.confirmation.Init()
 		case key.Matches(msg, o.keyMap.Up):
 			if o.cursor > 0 {
 				o.cursor--
@@ -70,12 +97,51 @@ func (o Operation) Update(msg tea.Msg) (operations.OperationWithOverlay, tea.Cmd
 	return o, o.updateSelection()
 }
 
+// compare shows the differences between the marked version of the change and the version at the cursor
+func (o Operation) compare() tea.Cmd {
+	var marked []string
+	for i, row := range o.rows {
+		if row.IsSelected && i != o.cursor {
+			marked = append(marked, row.Commit.CommitId)
+		}
+	}
+	if len(marked) != 1 {
+		err := errors.New("mark exactly one other version to compare with")
+		return func() tea.Msg { return common.CommandCompletedMsg{Err: err} }
+	}
+	return common.ShowCompareDiff(marked[0], o.rows[o.cursor].Commit.CommitId)
+}
+
+// interdiff shows how the changes of the version at the cursor differ from the marked version, or from the previous version
+func (o Operation) interdiff() tea.Cmd {
+	from := o.cursor + 1
+	for i, row := range o.rows {
+		if row.IsSelected && i != o.cursor {
+			from = i
+			break
+		}
+	}
+	if from >= len(o.rows) {
+		return func() tea.Msg {
+			return common.CommandCompletedMsg{Err: errors.New("there is no previous version to compare with")}
+		}
+	}
+	fromCommitId, toCommitId := o.rows[from].Commit.CommitId, o.rows[o.cursor].Commit.CommitId
+	return func() tea.Msg {
+		output, err := o.context.RunCommandImmediate(jj.Interdiff(fromCommitId, toCommitId))
+		if err != nil {
+			return common.CommandCompletedMsg{Output: string(output), Err: err}
+		}
+		return common.ShowDiffMsg(output)
+	}
+}
+
 func (o Operation) updateSelection() tea.Cmd {
 	if o.rows == nil {
 		return nil
 	}
 
-	return o.context.SetSelectedItem(context.SelectedRevision{ChangeId: o.rows[o.cursor].Commit.CommitId})
+	return o.context.SetSelectedItem(context.SelectedRevision{ChangeId: o.rows[o.cursor].Commit.CommitId, CommitId: o.rows[o.cursor].Commit.CommitId})
 }
 
 func (o Operation) RenderPosition() operations.RenderPosition {
@@ -95,6 +161,7 @@ func (o Operation) Render() string {
 			Palette:       common.DefaultPalette,
 			Op:            &operations.Default{},
 			IsHighlighted: i == o.cursor,
+			IsSelected:    row.IsSelected,
 			Width:         o.width,
 		}
 
@@ -120,6 +187,9 @@ func (o Operation) Render() string {
 
 	content := w.String(o.viewRange.start, o.viewRange.end)
 	content = lipgloss.PlaceHorizontal(o.width, lipgloss.Left, content)
+	if o.confirmation != nil {
+		content = lipgloss.JoinVertical(lipgloss.Left, content, o.confirmation.View())
+	}
 	return content
 }
 
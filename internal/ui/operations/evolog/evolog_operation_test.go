@@ -0,0 +1,74 @@
+package evolog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/graph"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOperation(c *test.TestContext) *Operation {
+	op, _ := NewOperation(c, "kmtqprws", 80, 20)
+	op.rows = []graph.Row{
+		{Commit: &jj.Commit{ChangeId: "kmtqprws", CommitId: "newest"}},
+		{Commit: &jj.Commit{ChangeId: "kmtqprws", CommitId: "middle"}},
+		{Commit: &jj.Commit{ChangeId: "kmtqprws", CommitId: "oldest"}},
+	}
+	return op
+}
+
+func TestRestoreOlderVersion(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.RestoreFrom("middle", "kmtqprws"))
+	defer c.Verify()
+
+	tm := teatest.NewTestModel(t, test.OperationHost{Operation: newTestOperation(c)})
+	tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	tm.Type("r")
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("to version middle"))
+	})
+	tm.Type("y")
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("closed"))
+	})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func TestInterdiffWithPreviousVersion(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.Interdiff("middle", "newest")).SetOutput([]byte("interdiff"))
+	defer c.Verify()
+
+	_, cmd := newTestOperation(c).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	assert.Equal(t, common.ShowDiffMsg("interdiff"), cmd())
+}
+
+func TestInterdiffFailure(t *testing.T) {
+	c := test.NewTestContext(t)
+	err := errors.New("exit status 1")
+	c.Expect(jj.Interdiff("middle", "newest")).SetOutput([]byte("Error: Revision not found")).SetError(err)
+	defer c.Verify()
+
+	_, cmd := newTestOperation(c).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	assert.Equal(t, common.CommandCompletedMsg{Output: "Error: Revision not found", Err: err}, cmd())
+}
+
+func TestInterdiffWithMarkedVersion(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.Interdiff("oldest", "newest")).SetOutput([]byte("interdiff"))
+	defer c.Verify()
+
+	op := newTestOperation(c)
+	op.rows[2].IsSelected = true
+	_, cmd := op.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	assert.Equal(t, common.ShowDiffMsg("interdiff"), cmd())
+}
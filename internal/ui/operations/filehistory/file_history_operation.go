@@ -0,0 +1,175 @@
+package filehistory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/operations"
+)
+
+// maxRenames limits how many renames are followed back in history
+const maxRenames = 10
+
+// StartMsg asks the revisions view to show the history of the given file
+type StartMsg struct {
+	File string
+}
+
+func Start(file string) tea.Cmd {
+	return func() tea.Msg {
+		return StartMsg{File: file}
+	}
+}
+
+type updateFileHistoryMsg struct {
+	entries []jj.FileLogEntry
+}
+
+type Operation struct {
+	context context.AppContext
+	file    string
+	entries []jj.FileLogEntry
+	cursor  int
+	width   int
+	height  int
+	keyMap  config.KeyMappings[key.Binding]
+}
+
+func (o *Operation) ShortHelp() []key.Binding {
+	return []key.Binding{o.keyMap.Up, o.keyMap.Down, o.keyMap.Cancel, o.keyMap.Diff}
+}
+
+func (o *Operation) FullHelp() [][]key.Binding {
+	return [][]key.Binding{o.ShortHelp()}
+}
+
+func (o *Operation) Update(msg tea.Msg) (operations.OperationWithOverlay, tea.Cmd) {
+	switch msg := msg.(type) {
+	case updateFileHistoryMsg:
+		o.entries = msg.entries
+		o.cursor = 0
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, o.keyMap.Cancel):
+			return o, common.Close
+		case key.Matches(msg, o.keyMap.Diff):
+			if o.cursor >= len(o.entries) {
+				return o, nil
+			}
+			entry := o.entries[o.cursor]
+			return o, common.ShowRevisionDiff(entry.CommitId, entry.Path)
+		case key.Matches(msg, o.keyMap.Up):
+			if o.cursor > 0 {
+				o.cursor--
+			}
+		case key.Matches(msg, o.keyMap.Down):
+			if o.cursor < len(o.entries)-1 {
+				o.cursor++
+			}
+		default:
+			return o, nil
+		}
+	default:
+		return o, nil
+	}
+	return o, o.updateSelection()
+}
+
+func (o *Operation) updateSelection() tea.Cmd {
+	if o.cursor >= len(o.entries) {
+		return nil
+	}
+	entry := o.entries[o.cursor]
+	return o.context.SetSelectedItem(context.SelectedFile{ChangeId: entry.CommitId, CommitId: entry.CommitId, File: entry.Path})
+}
+
+func (o *Operation) RenderPosition() operations.RenderPosition {
+	return operations.RenderPositionAfter
+}
+
+func (o *Operation) Render() string {
+	title := common.DefaultPalette.Normal.Bold(true).Render(fmt.Sprintf("history of %s", o.file))
+	if o.entries == nil {
+		return lipgloss.JoinVertical(0, title, "loading")
+	}
+	if len(o.entries) == 0 {
+		return lipgloss.JoinVertical(0, title, common.DefaultPalette.Dimmed.Render("no revisions changed this file"))
+	}
+
+	h := max(o.height-5, 1)
+	start := max(0, o.cursor-h+1)
+	end := min(len(o.entries), start+h)
+	lines := []string{title}
+	for i := start; i < end; i++ {
+		entry := o.entries[i]
+		parts := []string{
+			common.DefaultPalette.ChangeId.Render(entry.ChangeId),
+			" ",
+			common.DefaultPalette.CommitId.Render(entry.CommitId),
+			" ",
+			common.DefaultPalette.Dimmed.Render(entry.Author + " " + entry.Timestamp),
+			" ",
+			strings.TrimSpace(entry.Description),
+		}
+		if entry.Path != o.file {
+			parts = append(parts, " ", common.DefaultPalette.Renamed.Render("("+entry.Path+")"))
+		}
+		line := lipgloss.JoinHorizontal(0, parts...)
+		if i == o.cursor {
+			line = lipgloss.NewStyle().Bold(true).Background(common.IntenseBlack).Render(line)
+		}
+		lines = append(lines, lipgloss.NewStyle().MaxWidth(o.width).Render(line))
+	}
+	return lipgloss.JoinVertical(0, lines...)
+}
+
+func (o *Operation) Name() string {
+	return "history"
+}
+
+// load follows the file back through renames by continuing with the original name
+// below the revision that renamed it
+func (o *Operation) load() tea.Msg {
+	var entries []jj.FileLogEntry
+	file, before := o.file, ""
+	for range maxRenames {
+		output, err := o.context.RunCommandImmediate(jj.FileLog(file, before))
+		if err != nil {
+			return common.CommandCompletedMsg{Output: string(output), Err: err}
+		}
+		found := jj.ParseFileLogOutput(string(output), file)
+		entries = append(entries, found...)
+		if len(found) == 0 {
+			break
+		}
+		oldest := found[len(found)-1]
+		summary, err := o.context.RunCommandImmediate(jj.Status(oldest.CommitId))
+		if err != nil {
+			break
+		}
+		source, renamed := jj.RenameSource(string(summary), file)
+		if !renamed {
+			break
+		}
+		file, before = source, oldest.CommitId
+	}
+	return updateFileHistoryMsg{entries: entries}
+}
+
+func NewOperation(context context.AppContext, file string, width int, height int) (*Operation, tea.Cmd) {
+	o := &Operation{
+		context: context,
+		keyMap:  context.KeyMap(),
+		file:    file,
+		width:   width,
+		height:  height,
+	}
+	return o, o.load
+}
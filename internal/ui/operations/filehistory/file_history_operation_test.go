@@ -0,0 +1,45 @@
+package filehistory
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileHistory_FollowsRenames(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.FileLog("new.go", "")).SetOutput([]byte("kmtqprws;1a2b3c4d;Jane Doe;2 days ago;rename file\n"))
+	c.Expect(jj.Status("1a2b3c4d")).SetOutput([]byte("R {old.go => new.go}\n"))
+	c.Expect(jj.FileLog("old.go", "1a2b3c4d")).SetOutput([]byte("nzsvnmyx;5e6f7a8b;John Doe;1 year ago;initial\n"))
+	c.Expect(jj.Status("5e6f7a8b")).SetOutput([]byte("A old.go\n"))
+	defer c.Verify()
+
+	op, cmd := NewOperation(c, "new.go", 80, 20)
+	tm := teatest.NewTestModel(t, test.OperationHost{Operation: op})
+	tm.Send(cmd())
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("(old.go)"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEsc})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func TestFileHistory_ShowsDiffOfFileAtRevision(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.FileLog("main.go", "")).SetOutput([]byte("kmtqprws;1a2b3c4d;Jane Doe;2 days ago;second\nnzsvnmyx;5e6f7a8b;John Doe;1 year ago;initial\n"))
+	c.Expect(jj.Status("5e6f7a8b")).SetOutput([]byte("A main.go\n"))
+	defer c.Verify()
+
+	op, cmd := NewOperation(c, "main.go", 80, 20)
+	op.Update(cmd())
+	op.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd = op.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	assert.Equal(t, common.ShowRevisionDiffMsg{Revision: "5e6f7a8b", File: "main.go"}, cmd())
+}
@@ -0,0 +1,100 @@
+package oplog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/idursun/jjui/internal/jj"
+)
+
+// filter narrows down the operation log, e.g. `rebase user:alice since:yesterday until:2024-05-02`.
+// The words without a prefix must all appear in the description, ignoring case.
+type filter struct {
+	words []string
+	user  string
+	since time.Time
+	until time.Time
+}
+
+var durationUnits = map[byte]time.Duration{
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+func parseFilter(query string, now time.Time) (filter, error) {
+	var f filter
+	for _, field := range strings.Fields(query) {
+		name, value, found := strings.Cut(field, ":")
+		var err error
+		switch {
+		case found && name == "user":
+			f.user = strings.ToLower(value)
+		case found && name == "since":
+			f.since, err = parseTime(value, now, false)
+		case found && name == "until":
+			f.until, err = parseTime(value, now, true)
+		default:
+			f.words = append(f.words, strings.ToLower(field))
+		}
+		if err != nil {
+			return filter{}, err
+		}
+	}
+	return f, nil
+}
+
+// parseTime accepts a date, today, yesterday or a duration before now (e.g. 30m, 2h, 3d, 1w).
+// Days are included in full when they are the end of the range.
+func parseTime(value string, now time.Time, endOfDay bool) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var day time.Time
+	switch value {
+	case "today":
+		day = today
+	case "yesterday":
+		day = today.AddDate(0, 0, -1)
+	default:
+		if t, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+			day = t
+		} else if len(value) > 1 {
+			unit, ok := durationUnits[value[len(value)-1]]
+			if n, err := strconv.Atoi(value[:len(value)-1]); ok && err == nil && n >= 0 {
+				return now.Add(-time.Duration(n) * unit), nil
+			}
+		}
+	}
+	if day.IsZero() {
+		return time.Time{}, fmt.Errorf("invalid time %q, use a date (2006-01-02), today, yesterday or a duration (e.g. 2h, 3d)", value)
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1), nil
+	}
+	return day, nil
+}
+
+func (f filter) isEmpty() bool {
+	return len(f.words) == 0 && f.user == "" && f.since.IsZero() && f.until.IsZero()
+}
+
+func (f filter) matches(info jj.OperationInfo) bool {
+	if f.user != "" && !strings.Contains(strings.ToLower(info.User), f.user) {
+		return false
+	}
+	if !f.since.IsZero() && info.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !info.Time.Before(f.until) {
+		return false
+	}
+	description := strings.ToLower(info.Description)
+	for _, word := range f.words {
+		if !strings.Contains(description, word) {
+			return false
+		}
+	}
+	return true
+}
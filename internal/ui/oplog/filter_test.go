@@ -0,0 +1,57 @@
+package oplog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+
+func TestParseFilter(t *testing.T) {
+	f, err := parseFilter("Rebase user:Alice since:yesterday until:2024-05-02", now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rebase"}, f.words)
+	assert.Equal(t, "alice", f.user)
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), f.since)
+	assert.Equal(t, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC), f.until)
+
+	f, err = parseFilter("since:2h", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-2*time.Hour), f.since)
+
+	_, err = parseFilter("since:someday", now)
+	assert.Error(t, err)
+	_, err = parseFilter("until:", now)
+	assert.Error(t, err)
+}
+
+func TestFilterMatches(t *testing.T) {
+	info := jj.OperationInfo{
+		User:        "alice@laptop",
+		Time:        time.Date(2024, 5, 2, 18, 30, 0, 0, time.UTC),
+		Description: "rebase commit 1234abcd",
+	}
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"", true},
+		{"REBASE", true},
+		{"rebase squash", false},
+		{"user:alice", true},
+		{"user:bob", false},
+		{"since:yesterday until:yesterday", true},
+		{"since:today", false},
+		{"until:2024-05-01", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			f, err := parseFilter(tt.query, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, f.matches(info))
+		})
+	}
+}
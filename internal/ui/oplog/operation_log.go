@@ -2,21 +2,46 @@ package oplog
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/confirmation"
 	"github.com/idursun/jjui/internal/ui/context"
 	"github.com/idursun/jjui/internal/ui/graph"
+	"github.com/idursun/jjui/internal/ui/rangediff"
 )
 
 var normalStyle = lipgloss.NewStyle()
 
+// maxSummaryLines limits how much of `op show` is shown when confirming an undo
+const maxSummaryLines = 10
+
 type updateOpLogMsg struct {
-	Rows []Row
+	Rows  []Row
+	Infos []jj.OperationInfo
+	// Limit is the number of operations that were requested, 0 when all of them were
+	Limit  int
+	Err    error
+	Output string
+}
+
+// modifiedMsg is sent once an operation is undone or abandoned
+type modifiedMsg struct{}
+
+// confirmUndoMsg carries the summary of the operation to confirm undoing it
+type confirmUndoMsg struct {
+	OperationId string
+	Summary     string
 }
 
 type viewRange struct {
@@ -24,17 +49,37 @@ type viewRange struct {
 	end   int
 }
 type Model struct {
-	context   context.AppContext
-	rows      []Row
-	cursor    int
-	keymap    config.KeyMappings[key.Binding]
-	viewRange *viewRange
-	width     int
-	height    int
+	context context.AppContext
+	// allRows are the loaded operations and rows are the ones that match the filter
+	allRows []Row
+	rows    []Row
+	infos   map[string]jj.OperationInfo
+	// marked are the operations marked to be compared, they're kept by id as the rows are replaced when filtering or loading more
+	marked map[string]bool
+	// limit is the number of operations to load, it grows a page at a time when scrolling past the last one
+	limit     int
+	loading   bool
+	exhausted bool
+	err       error
+	errOutput string
+	filter    filter
+	// filterQuery is the text the filter was parsed from
+	filterQuery  string
+	filtering    bool
+	input        textinput.Model
+	quickSearch  string
+	cursor       int
+	keymap       config.KeyMappings[key.Binding]
+	viewRange    *viewRange
+	width        int
+	height       int
+	confirmation *confirmation.Model
+	// modified is set when operations are undone or abandoned, so the revisions are refreshed on close
+	modified bool
 }
 
 func (m *Model) ShortHelp() []key.Binding {
-	return []key.Binding{m.keymap.Up, m.keymap.Down, m.keymap.Cancel, m.keymap.Diff, m.keymap.OpLog.Restore}
+	return []key.Binding{m.keymap.Up, m.keymap.Down, m.keymap.Cancel, m.keymap.Diff, m.keymap.ToggleSelect, m.keymap.Compare, m.keymap.OpLog.Restore, m.keymap.OpLog.RangeDiff, m.keymap.OpLog.Undo, m.keymap.OpLog.Abandon, m.keymap.OpLog.Filter, m.keymap.QuickSearch, m.keymap.QuickSearchCycle}
 }
 
 func (m *Model) FullHelp() [][]key.Binding {
@@ -61,16 +106,74 @@ func (m *Model) Init() tea.Cmd {
 	return m.load()
 }
 
+// IsFocused is true while the filter is being edited
+func (m *Model) IsFocused() bool {
+	return m.filtering
+}
+
 func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case updateOpLogMsg:
-		m.rows = msg.Rows
+		m.loading = false
+		if msg.Err != nil {
+			m.err, m.errOutput = msg.Err, msg.Output
+			return m, nil
+		}
+		m.err, m.errOutput = nil, ""
+		m.allRows = msg.Rows
+		m.exhausted = msg.Limit <= 0 || len(msg.Rows) < msg.Limit
+		if msg.Infos != nil {
+			m.infos = make(map[string]jj.OperationInfo, len(msg.Infos))
+			for _, info := range msg.Infos {
+				m.infos[info.OperationId] = info
+			}
+		}
+		m.applyFilter()
+		m.viewRange.start = 0
+		m.viewRange.end = 0
+		// the filter may only match older operations
+		if len(m.rows) == 0 && !m.filter.isEmpty() && !m.exhausted {
+			m.loading = true
+			m.limit += config.Current.OpLog.Limit
+			return m, m.load()
+		}
+	case common.QuickSearchMsg:
+		m.quickSearch = string(msg)
+		m.cursor = m.search(0)
 		m.viewRange.start = 0
 		m.viewRange.end = 0
+	case modifiedMsg:
+		m.modified = true
+	case confirmUndoMsg:
+		return m, m.confirm(msg.Summary+"\n\nAre you sure you want to undo this operation?", jj.OpUndo(msg.OperationId))
+	case common.RefreshMsg:
+		return m, m.load()
+	case confirmation.CloseMsg:
+		m.confirmation = nil
+		return m, nil
 	case tea.KeyMsg:
+		if m.confirmation != nil {
+			_, cmd := m.confirmation.Update(msg)
+			return m, cmd
+		}
+		if m.filtering {
+			return m, m.updateFilter(msg)
+		}
 		switch {
+		case key.Matches(msg, m.keymap.Cancel) && m.filterQuery != "":
+			return m, m.setFilter("")
 		case key.Matches(msg, m.keymap.Cancel):
+			if m.modified {
+				return m, tea.Sequence(common.Close, common.Refresh)
+			}
 			return m, common.Close
+		case key.Matches(msg, m.keymap.OpLog.Filter) && m.err == nil:
+			m.filtering = true
+			m.input.SetValue(m.filterQuery)
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		case len(m.rows) == 0:
+			return m, nil
 		case key.Matches(msg, m.keymap.Up):
 			if m.cursor > 0 {
 				m.cursor--
@@ -78,12 +181,35 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 		case key.Matches(msg, m.keymap.Down):
 			if m.cursor < len(m.rows)-1 {
 				m.cursor++
+			} else if !m.exhausted && !m.loading {
+				m.loading = true
+				m.limit += config.Current.OpLog.Limit
+				return m, m.load()
 			}
+		case key.Matches(msg, m.keymap.QuickSearchCycle):
+			m.cursor = m.search(m.cursor + 1)
 		case key.Matches(msg, m.keymap.Diff):
 			return m, func() tea.Msg {
 				output, _ := m.context.RunCommandImmediate(jj.OpShow(m.rows[m.cursor].OperationId))
 				return common.ShowDiffMsg(output)
 			}
+		case key.Matches(msg, m.keymap.ToggleSelect):
+			if m.cursor < len(m.rows) {
+				operationId := m.rows[m.cursor].OperationId
+				if m.marked[operationId] {
+					delete(m.marked, operationId)
+				} else {
+					m.marked[operationId] = true
+				}
+			}
+		case key.Matches(msg, m.keymap.Compare):
+			return m, m.compare()
+		case key.Matches(msg, m.keymap.OpLog.RangeDiff):
+			return m, rangediff.Show(m.context, "@", m.rows[m.cursor].OperationId)
+		case key.Matches(msg, m.keymap.OpLog.Undo):
+			return m, m.summary(m.rows[m.cursor].OperationId)
+		case key.Matches(msg, m.keymap.OpLog.Abandon):
+			return m, m.abandon()
 		case key.Matches(msg, m.keymap.OpLog.Restore):
 			return m, tea.Batch(common.Close, m.context.RunCommand(jj.OpRestore(m.rows[m.cursor].OperationId), common.Refresh))
 		}
@@ -92,18 +218,170 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 }
 
 func (m *Model) updateSelection() tea.Cmd {
-	if m.rows == nil {
+	if len(m.rows) == 0 {
 		return nil
 	}
-	return m.context.SetSelectedItem(context.SelectedOperation{OperationId: m.rows[m.cursor].OperationId})
+	return m.context.SetSelectedItem(context.SelectedOperation{OperationId: m.rows[m.cursor].OperationId, IsCurrent: m.isCurrent(m.cursor)})
+}
+
+// isCurrent reports whether the row is the operation the repository is at, which is the first one when it's not filtered out
+func (m *Model) isCurrent(row int) bool {
+	return len(m.allRows) > 0 && m.rows[row].OperationId == m.allRows[0].OperationId
+}
+
+func (m *Model) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keymap.Cancel):
+		m.filtering = false
+		m.input.Blur()
+		return nil
+	case key.Matches(msg, m.keymap.Apply):
+		m.filtering = false
+		m.input.Blur()
+		return m.setFilter(m.input.Value())
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return cmd
+}
+
+// setFilter reloads the operations with the information needed to filter them
+func (m *Model) setFilter(query string) tea.Cmd {
+	f, err := parseFilter(query, time.Now())
+	if err != nil {
+		return func() tea.Msg { return common.CommandCompletedMsg{Err: err} }
+	}
+	m.filter, m.filterQuery = f, strings.TrimSpace(query)
+	m.cursor = 0
+	if f.isEmpty() {
+		m.applyFilter()
+		return m.updateSelection()
+	}
+	m.loading = true
+	return m.load()
+}
+
+func (m *Model) applyFilter() {
+	if m.filter.isEmpty() {
+		m.rows = m.allRows
+	} else {
+		m.rows = nil
+		for _, row := range m.allRows {
+			if info, ok := m.infos[row.OperationId]; ok && m.filter.matches(info) {
+				m.rows = append(m.rows, row)
+			}
+		}
+	}
+	m.cursor = min(m.cursor, max(len(m.rows)-1, 0))
+}
+
+func (m *Model) search(startIndex int) int {
+	if m.quickSearch == "" || len(m.rows) == 0 {
+		return m.cursor
+	}
+	n := len(m.rows)
+	for i := startIndex; i < n+startIndex; i++ {
+		c := i % n
+		for _, line := range m.rows[c].Lines {
+			for _, segment := range line.Segments {
+				if segment.Text != "" && strings.Contains(segment.Text, m.quickSearch) {
+					return c
+				}
+			}
+		}
+	}
+	return m.cursor
+}
+
+// summary loads the beginning of `op show` for the operation to confirm undoing it
+func (m *Model) summary(operationId string) tea.Cmd {
+	return func() tea.Msg {
+		output, _ := m.context.RunCommandImmediate(jj.OpShow(operationId))
+		lines := strings.Split(string(output), "\n")
+		if len(lines) > maxSummaryLines {
+			lines = append(lines[:maxSummaryLines], fmt.Sprintf("(%d more lines)", len(lines)-maxSummaryLines))
+		}
+		return confirmUndoMsg{OperationId: operationId, Summary: strings.Join(lines, "\n")}
+	}
+}
+
+func (m *Model) confirm(message string, args jj.CommandArgs) tea.Cmd {
+	model := confirmation.New(message)
+	modified := func() tea.Msg { return modifiedMsg{} }
+	model.AddOption("Yes", m.context.RunCommand(args, modified, common.Refresh, confirmation.Close), key.NewBinding(key.WithKeys("y")))
+	model.AddOption("No", confirmation.Close, key.NewBinding(key.WithKeys("n", "esc")))
+	m.confirmation = &model
+	return m.confirmation.Init()
+}
+
+// abandon removes the operation at the cursor and all operations before it,
+// or the operations between the marked one and the one at the cursor
+func (m *Model) abandon() tea.Cmd {
+	older, newer := m.cursor, m.cursor
+	for i, row := range m.rows {
+		if m.marked[row.OperationId] && i != m.cursor {
+			older, newer = max(i, m.cursor), min(i, m.cursor)
+			break
+		}
+	}
+	if m.isCurrent(newer) {
+		err := errors.New("the current operation can't be abandoned")
+		return func() tea.Msg { return common.CommandCompletedMsg{Err: err} }
+	}
+	olderId, newerId := m.rows[older].OperationId, m.rows[newer].OperationId
+	if older == newer {
+		return m.confirm(fmt.Sprintf("Are you sure you want to abandon operation %s and all operations before it?", newerId), jj.OpAbandon(".."+newerId))
+	}
+	// the parent of the older operation is excluded so that both ends are abandoned
+	return m.confirm(fmt.Sprintf("Are you sure you want to abandon the operations from %s to %s?", olderId, newerId), jj.OpAbandon(olderId+"-.."+newerId))
+}
+
+// compare shows the changes between the two marked operations, or between the marked operation and the one at the cursor
+func (m *Model) compare() tea.Cmd {
+	var marked []int
+	for i, row := range m.rows {
+		if m.marked[row.OperationId] {
+			marked = append(marked, i)
+		}
+	}
+	if len(marked) == 1 && marked[0] != m.cursor {
+		marked = append(marked, m.cursor)
+	}
+	if len(marked) != 2 {
+		err := errors.New("mark two operations to compare")
+		return func() tea.Msg { return common.CommandCompletedMsg{Err: err} }
+	}
+	// the older operation is further down in the log
+	slices.Sort(marked)
+	from, to := m.rows[marked[1]].OperationId, m.rows[marked[0]].OperationId
+	return func() tea.Msg {
+		output, err := m.context.RunCommandImmediate(jj.OpDiff(from, to))
+		if err != nil {
+			return common.CommandCompletedMsg{Output: string(output), Err: err}
+		}
+		return common.ShowDiffMsg(output)
+	}
 }
 
 func (m *Model) View() string {
-	if m.rows == nil {
+	if m.err != nil {
+		message := common.DefaultPalette.StatusError.Render("failed to load the operation log: "+m.err.Error()) + "\n" + m.errOutput
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, message)
+	}
+	if m.allRows == nil {
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, "loading")
 	}
 
 	h := m.height
+	header := m.headerView()
+	if header != "" {
+		h = max(h-1, 1)
+	}
+	confirmationView := ""
+	if m.confirmation != nil {
+		confirmationView = m.confirmation.View()
+		h = max(h-lipgloss.Height(confirmationView), 1)
+	}
 	viewHeight := m.viewRange.end - m.viewRange.start
 	if viewHeight != h {
 		m.viewRange.end = m.viewRange.start + h
@@ -122,7 +400,7 @@ func (m *Model) View() string {
 				continue
 			}
 		}
-		RenderRow(&w, row, isHighlighted, m.width)
+		RenderRow(&w, row, isHighlighted, m.marked[row.OperationId], m.width)
 		if isHighlighted {
 			selectedLineEnd = w.LineCount()
 		}
@@ -139,29 +417,68 @@ func (m *Model) View() string {
 	}
 
 	content := w.String(m.viewRange.start, m.viewRange.end)
+	if len(m.rows) == 0 {
+		content = common.DefaultPalette.Dimmed.Render("no operations match the filter")
+	}
 	content = lipgloss.PlaceHorizontal(m.width, lipgloss.Left, content)
+	if header != "" {
+		content = lipgloss.JoinVertical(lipgloss.Left, header, content)
+	}
+	if confirmationView != "" {
+		content = lipgloss.JoinVertical(lipgloss.Left, content, confirmationView)
+	}
 	return normalStyle.MaxWidth(m.width).Render(content)
 }
 
+// headerView shows the filter while it's edited or applied, and whether there are more operations to load
+func (m *Model) headerView() string {
+	if m.filtering {
+		return m.input.View()
+	}
+	if m.filterQuery == "" {
+		return ""
+	}
+	more := ""
+	if !m.exhausted {
+		more = fmt.Sprintf(" in the last %d", len(m.allRows))
+	}
+	status := fmt.Sprintf("filter: %s (%d operations%s)", m.filterQuery, len(m.rows), more)
+	return common.DefaultPalette.Dimmed.Render(status)
+}
+
 func (m *Model) load() tea.Cmd {
+	limit, filtered := m.limit, !m.filter.isEmpty()
 	return func() tea.Msg {
-		output, err := m.context.RunCommandImmediate(jj.OpLog(config.Current.OpLog.Limit))
+		output, err := m.context.RunCommandImmediate(jj.OpLog(limit))
 		if err != nil {
-			panic(err)
+			return updateOpLogMsg{Err: err, Output: string(output)}
 		}
-
-		rows := ParseRows(bytes.NewReader(output))
-		return updateOpLogMsg{Rows: rows}
+		msg := updateOpLogMsg{Rows: ParseRows(bytes.NewReader(output)), Limit: limit}
+		if filtered {
+			output, err := m.context.RunCommandImmediate(jj.OpLogInfo(limit))
+			if err != nil {
+				return updateOpLogMsg{Err: err, Output: string(output)}
+			}
+			msg.Infos = jj.ParseOpLogInfoOutput(string(output))
+		}
+		return msg
 	}
 }
 
 func New(context context.AppContext, width int, height int) *Model {
 	keyMap := context.KeyMap()
 	v := viewRange{start: 0, end: 0}
+	input := textinput.New()
+	input.Prompt = "filter: "
+	input.Placeholder = "text user:name since:yesterday until:2006-01-02"
+	input.PromptStyle = common.DefaultPalette.ChangeId
 	return &Model{
 		context:   context,
 		keymap:    keyMap,
 		rows:      nil,
+		marked:    make(map[string]bool),
+		limit:     config.Current.OpLog.Limit,
+		input:     input,
 		cursor:    0,
 		viewRange: &v,
 		width:     width,
@@ -0,0 +1,292 @@
+package oplog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/screen"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	up      = tea.KeyMsg{Type: tea.KeyUp}
+	down    = tea.KeyMsg{Type: tea.KeyDown}
+	mark    = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	compare = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("=")}
+)
+
+func newModel(c *test.TestContext) *Model {
+	m := New(c, 80, 20)
+	m.Update(updateOpLogMsg{Rows: []Row{{OperationId: "current"}, {OperationId: "previous"}, {OperationId: "oldest"}}})
+	return m
+}
+
+func TestCompare_MarkedWithCursor(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.OpDiff("oldest", "current")).SetOutput([]byte("changes"))
+	defer c.Verify()
+
+	m := newModel(c)
+	m.Update(down)
+	m.Update(down)
+	m.Update(mark)
+	m.Update(up)
+	m.Update(up)
+	_, cmd := m.Update(compare)
+	assert.Equal(t, common.ShowDiffMsg("changes"), cmd())
+}
+
+func TestCompare_TwoMarked(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.OpDiff("oldest", "previous")).SetOutput([]byte("changes"))
+	defer c.Verify()
+
+	m := newModel(c)
+	m.Update(down)
+	m.Update(mark)
+	m.Update(down)
+	m.Update(mark)
+	m.Update(up)
+	m.Update(up)
+	_, cmd := m.Update(compare)
+	assert.Equal(t, common.ShowDiffMsg("changes"), cmd())
+}
+
+func TestCompare_NothingMarked(t *testing.T) {
+	c := test.NewTestContext(t)
+	defer c.Verify()
+
+	m := newModel(c)
+	_, cmd := m.Update(compare)
+	msg, ok := cmd().(common.CommandCompletedMsg)
+	assert.True(t, ok)
+	assert.Error(t, msg.Err)
+}
+
+// run executes the command and the commands it batches, returning the messages
+func run(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, cmd := range batch {
+			msgs = append(msgs, run(cmd)...)
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
+func TestUndo(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.OpShow("previous")).SetOutput([]byte("describe commit abc"))
+	c.Expect(jj.OpUndo("previous"))
+	c.Expect(jj.OpLog(config.Current.OpLog.Limit))
+	defer c.Verify()
+
+	m := newModel(c)
+	m.Update(down)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	assert.Nil(t, m.confirmation, "the summary is loaded in the background")
+	m.Update(cmd())
+	assert.Contains(t, m.View(), "describe commit abc")
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	for _, msg := range run(cmd) {
+		_, cmd = m.Update(msg)
+		run(cmd)
+	}
+	assert.Nil(t, m.confirmation)
+
+	// closing the oplog refreshes the revisions after the undo
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.NotEqual(t, common.CloseViewMsg{}, cmd())
+}
+
+func TestRefresh_DoesNotRefreshOnClose(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.OpLog(config.Current.OpLog.Limit))
+	defer c.Verify()
+
+	m := newModel(c)
+	_, cmd := m.Update(common.RefreshMsg{})
+	run(cmd)
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, common.CloseViewMsg{}, cmd())
+}
+
+func TestAbandon(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []tea.KeyMsg
+		expected string
+	}{
+		{"operation and older ones", []tea.KeyMsg{down}, "..previous"},
+		{"marked range", []tea.KeyMsg{down, mark, down}, "oldest-..previous"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := test.NewTestContext(t)
+			c.Expect(jj.OpAbandon(tt.expected))
+			defer c.Verify()
+
+			m := newModel(c)
+			for _, k := range tt.keys {
+				m.Update(k)
+			}
+			m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+			assert.NotNil(t, m.confirmation)
+			_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+			run(cmd)
+		})
+	}
+}
+
+func TestAbandon_CurrentOperation(t *testing.T) {
+	c := test.NewTestContext(t)
+	defer c.Verify()
+
+	m := newModel(c)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	assert.Nil(t, m.confirmation)
+	msg, ok := cmd().(common.CommandCompletedMsg)
+	assert.True(t, ok)
+	assert.Error(t, msg.Err)
+}
+
+func TestLoad_Error(t *testing.T) {
+	c := test.NewTestContext(t)
+	defer c.Verify()
+
+	m := New(c, 80, 20)
+	m.Update(updateOpLogMsg{Err: errors.New("exit status 1"), Output: "repository is locked"})
+	view := m.View()
+	assert.Contains(t, view, "exit status 1")
+	assert.Contains(t, view, "repository is locked")
+}
+
+func TestLoad_MoreOnScroll(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.OpLog(4))
+	defer c.Verify()
+
+	defer func(limit int) { config.Current.OpLog.Limit = limit }(config.Current.OpLog.Limit)
+	config.Current.OpLog.Limit = 2
+	m := New(c, 80, 20)
+	m.Update(updateOpLogMsg{Rows: []Row{{OperationId: "current"}, {OperationId: "previous"}}, Limit: 2})
+
+	m.Update(down)
+	_, cmd := m.Update(down)
+	assert.Equal(t, 4, m.limit)
+	cmd()
+
+	m.Update(updateOpLogMsg{Rows: []Row{{OperationId: "current"}, {OperationId: "previous"}, {OperationId: "oldest"}}, Limit: 4})
+	assert.True(t, m.exhausted)
+	m.Update(down)
+	assert.Equal(t, 2, m.cursor)
+}
+
+func TestFilter(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.OpLog(config.Current.OpLog.Limit))
+	c.Expect(jj.OpLogInfo(config.Current.OpLog.Limit)).SetOutput([]byte(
+		"current;alice;2024-05-03T10:00:00+00:00;snapshot working copy\n" +
+			"previous;alice;2024-05-02T10:00:00+00:00;rebase commit abc\n" +
+			"oldest;bob;2024-05-01T10:00:00+00:00;rebase commit def\n"))
+	defer c.Verify()
+
+	m := newModel(c)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	assert.True(t, m.IsFocused())
+	for _, r := range "rebase user:alice" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, m.IsFocused())
+	msg := cmd().(updateOpLogMsg)
+	// the parsed rows are replaced to keep the test independent of the op log output
+	msg.Rows = []Row{{OperationId: "current"}, {OperationId: "previous"}, {OperationId: "oldest"}}
+	m.Update(msg)
+
+	assert.Len(t, m.rows, 1)
+	assert.Equal(t, "previous", m.rows[0].OperationId)
+	assert.Contains(t, m.View(), "filter: rebase user:alice (1 operations)")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Len(t, m.rows, 3)
+}
+
+func TestFilter_LoadsMoreUntilMatched(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.OpLog(4))
+	c.Expect(jj.OpLogInfo(4))
+	defer c.Verify()
+
+	defer func(limit int) { config.Current.OpLog.Limit = limit }(config.Current.OpLog.Limit)
+	config.Current.OpLog.Limit = 2
+	m := New(c, 80, 20)
+	m.filter, _ = parseFilter("rebase", time.Now())
+	m.filterQuery = "rebase"
+	_, cmd := m.Update(updateOpLogMsg{
+		Rows:  []Row{{OperationId: "current"}, {OperationId: "previous"}},
+		Infos: []jj.OperationInfo{{OperationId: "current", Description: "snapshot working copy"}, {OperationId: "previous", Description: "snapshot working copy"}},
+		Limit: 2,
+	})
+	assert.Empty(t, m.rows)
+	assert.Equal(t, 4, m.limit, "the next page is loaded when nothing matches")
+	assert.NotNil(t, cmd)
+	cmd()
+
+	m.Update(updateOpLogMsg{
+		Rows:  []Row{{OperationId: "current"}, {OperationId: "previous"}, {OperationId: "oldest"}},
+		Infos: []jj.OperationInfo{{OperationId: "current", Description: "snapshot working copy"}, {OperationId: "previous", Description: "snapshot working copy"}, {OperationId: "oldest", Description: "rebase commit def"}},
+		Limit: 4,
+	})
+	assert.Len(t, m.rows, 1)
+	assert.Equal(t, "oldest", m.rows[0].OperationId)
+}
+
+func TestMarks_KeptWhenRowsAreReplaced(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.OpDiff("oldest", "current")).SetOutput([]byte("changes"))
+	defer c.Verify()
+
+	m := newModel(c)
+	m.Update(down)
+	m.Update(down)
+	m.Update(mark)
+	// filtering and loading more operations replace the rows
+	m.filter, _ = parseFilter("rebase", time.Now())
+	m.Update(updateOpLogMsg{
+		Rows:  []Row{{OperationId: "current"}, {OperationId: "previous"}, {OperationId: "oldest"}},
+		Infos: []jj.OperationInfo{{OperationId: "current", Description: "rebase"}, {OperationId: "previous"}, {OperationId: "oldest", Description: "rebase"}},
+	})
+	assert.Len(t, m.rows, 2)
+	m.Update(up)
+	_, cmd := m.Update(compare)
+	assert.Equal(t, common.ShowDiffMsg("changes"), cmd())
+}
+
+func TestQuickSearch(t *testing.T) {
+	c := test.NewTestContext(t)
+	defer c.Verify()
+
+	m := New(c, 80, 20)
+	row := func(id string, description string) Row {
+		return Row{OperationId: id, Lines: []*RowLine{{Segments: []*screen.Segment{{Text: id}, {Text: description}}}}}
+	}
+	m.Update(updateOpLogMsg{Rows: []Row{row("current", "snapshot working copy"), row("previous", "rebase commit abc"), row("oldest", "rebase commit def")}})
+	m.Update(common.QuickSearchMsg("rebase"))
+	assert.Equal(t, 1, m.cursor)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("'")})
+	assert.Equal(t, 2, m.cursor)
+}
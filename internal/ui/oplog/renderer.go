@@ -7,18 +7,29 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/ui/common"
 )
 
-func RenderRow(r io.Writer, row Row, highlighted bool, width int) {
+func RenderRow(r io.Writer, row Row, highlighted bool, marked bool, width int) {
 	highlightColor := lipgloss.AdaptiveColor{
 		Light: config.Current.UI.HighlightLight,
 		Dark:  config.Current.UI.HighlightDark,
 	}
 	highlightSeq := lipgloss.ColorProfile().FromColor(highlightColor).Sequence(true)
 
+	markerShown := !marked
 	for _, rowLine := range row.Lines {
 		lw := strings.Builder{}
-		for _, segment := range rowLine.Segments {
+		idIndex := rowLine.FindIdIndex()
+		for i, segment := range rowLine.Segments {
+			if !markerShown && i == idIndex {
+				marker := common.DefaultPalette.Added
+				if highlighted {
+					marker = marker.Background(highlightColor)
+				}
+				fmt.Fprint(&lw, marker.Render("✓ "))
+				markerShown = true
+			}
 			if highlighted {
 				fmt.Fprint(&lw, segment.WithBackground(highlightSeq).String())
 			} else {
@@ -0,0 +1,97 @@
+package preview
+
+import (
+	"container/list"
+	"slices"
+)
+
+// cacheSize is the number of previews kept in memory
+const cacheSize = 32
+
+// cacheKey identifies a preview. Commit ids change whenever a revision is rewritten,
+// only the bookmarks and tags in the content can change without it.
+type cacheKey struct {
+	commitId string
+	format   string
+	file     string
+}
+
+type cacheEntry struct {
+	key     cacheKey
+	content string
+	// refs are the bookmarks and tags of the commit when the content was loaded
+	refs string
+}
+
+// cache keeps the most recently used previews
+type cache struct {
+	capacity int
+	entries  map[cacheKey]*list.Element
+	order    *list.List
+}
+
+func newCache(capacity int) *cache {
+	return &cache{
+		capacity: capacity,
+		entries:  make(map[cacheKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *cache) get(key cacheKey) (string, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).content, true
+}
+
+// put ignores keys without a commit id as their content can change
+func (c *cache) put(key cacheKey, content string, refs string) {
+	if key.commitId == "" {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		entry.content = content
+		entry.refs = refs
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, content: content, refs: refs})
+	if c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// retain drops the previews of the commits that are no longer visible, i.e. the ones that were rewritten
+func (c *cache) retain(commitIds []string) {
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if !slices.Contains(commitIds, element.Value.(*cacheEntry).key.commitId) {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// dropChangedRefs drops the previews of the commits whose bookmarks or tags changed, it reports whether any were dropped
+func (c *cache) dropChangedRefs(refsOf func(commitId string) string) bool {
+	dropped := false
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		entry := element.Value.(*cacheEntry)
+		if refsOf(entry.key.commitId) != entry.refs {
+			c.remove(element)
+			dropped = true
+		}
+		element = next
+	}
+	return dropped
+}
+
+func (c *cache) remove(element *list.Element) {
+	delete(c.entries, element.Value.(*cacheEntry).key)
+	c.order.Remove(element)
+}
@@ -2,17 +2,23 @@ package preview
 
 import (
 	"bufio"
+	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/internal/ui/diff"
 )
 
 type viewRange struct {
@@ -28,12 +34,36 @@ type Model struct {
 	height           int
 	content          string
 	contentLineCount int
-	context          context.AppContext
-	keyMap           config.KeyMappings[key.Binding]
+	files            []jj.DiffFile
+	// rendered is the side by side layout of the content for renderedWidth
+	rendered      string
+	renderedWidth int
+	cache         *cache
+	// refs are the bookmarks and tags of the visible commits by their full commit ids
+	refs map[string]string
+	// prefetch are the commits whose previews are loaded after the selected one
+	prefetch []string
+	// item is the selected item the content belongs to
+	item    context.SelectedItem
+	xOffset int
+	// searchStart is the line the view was at when the search started
+	searchStart int
+	searching   bool
+	input       textinput.Model
+	query       string
+	// matches are the matching lines of the displayed content, they are found again when matchedWidth changes
+	matches      []int
+	match        int
+	matchedWidth int
+	context      context.AppContext
+	keyMap       config.KeyMappings[key.Binding]
 }
 
 const DebounceTime = 10 * time.Millisecond
 
+// horizontalScrollStep is the number of columns scrolled left or right at a time
+const horizontalScrollStep = 10
+
 var border = lipgloss.NewStyle().Border(lipgloss.NormalBorder())
 
 type refreshPreviewContentMsg struct {
@@ -41,6 +71,28 @@ type refreshPreviewContentMsg struct {
 }
 
 type updatePreviewContentMsg struct {
+	Tag     int
+	Key     cacheKey
+	Content string
+}
+
+// refsLoadedMsg carries the bookmarks and tags of the visible commits
+type refsLoadedMsg struct {
+	Refs map[string]string
+}
+
+// UpdatesCache reports whether the preview should get the message while it's hidden, to keep its cache up to date
+func UpdatesCache(msg tea.Msg) bool {
+	switch msg.(type) {
+	case common.RevisionsLoadedMsg, refsLoadedMsg:
+		return true
+	}
+	return false
+}
+
+// cachePreviewContentMsg carries a prefetched preview
+type cachePreviewContentMsg struct {
+	Key     cacheKey
 	Content string
 }
 
@@ -65,12 +117,36 @@ func (m *Model) Init() tea.Cmd {
 	return nil
 }
 
+// IsFocused is true while the search query is being typed
+func (m *Model) IsFocused() bool {
+	return m.searching
+}
+
 func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case updatePreviewContentMsg:
-		m.content = msg.Content
-		m.contentLineCount = strings.Count(m.content, "\n")
-		m.reset()
+		m.cache.put(msg.Key, msg.Content, m.refsOf(msg.Key.commitId))
+		// the selection has changed while the content was loading
+		if msg.Tag != m.tag {
+			return m, nil
+		}
+		m.setContent(msg.Content)
+		return m, m.prefetchNeighbours()
+	case cachePreviewContentMsg:
+		m.cache.put(msg.Key, msg.Content, m.refsOf(msg.Key.commitId))
+	case common.PrefetchMsg:
+		m.prefetch = msg.CommitIds
+	case common.RevisionsLoadedMsg:
+		m.cache.retain(msg.CommitIds)
+		return m, m.loadRefs(msg.CommitIds)
+	case refsLoadedMsg:
+		m.refs = msg.Refs
+		// the shown preview is loaded again if it was dropped
+		if m.cache.dropChangedRefs(m.refsOf) {
+			m.tag++
+			tag := m.tag
+			return m, func() tea.Msg { return refreshPreviewContentMsg{Tag: tag} }
+		}
 	case common.SelectionChangedMsg, common.RefreshMsg:
 		m.tag++
 		tag := m.tag
@@ -79,26 +155,70 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 		})
 	case refreshPreviewContentMsg:
 		if m.tag == msg.Tag {
+			tag := m.tag
 			switch msg := m.context.SelectedItem().(type) {
 			case context.SelectedFile:
+				key := m.cacheKey(msg.CommitId, msg.File)
+				if content, ok := m.cache.get(key); ok {
+					m.setContent(content)
+					return m, nil
+				}
 				return m, func() tea.Msg {
-					output, _ := m.context.RunCommandImmediate(jj.Diff(msg.ChangeId, msg.File))
-					return updatePreviewContentMsg{Content: string(output)}
+					output, err := m.context.RunCommandImmediate(jj.Diff(msg.ChangeId, msg.File, m.diffArgs()...))
+					return m.loaded(tag, key, string(output), err)
 				}
 			case context.SelectedRevision:
+				key := m.cacheKey(msg.CommitId, "")
+				if content, ok := m.cache.get(key); ok {
+					m.setContent(content)
+					return m, m.prefetchNeighbours()
+				}
 				return m, func() tea.Msg {
-					output, _ := m.context.RunCommandImmediate(jj.Show(msg.ChangeId))
-					return updatePreviewContentMsg{Content: string(output)}
+					output, err := m.context.RunCommandImmediate(jj.Show(msg.ChangeId, m.diffArgs()...))
+					return m.loaded(tag, key, string(output), err)
 				}
 			case context.SelectedOperation:
+				// older operations show everything that changed since them
+				args := jj.OpDiff(msg.OperationId, "@")
+				if msg.IsCurrent {
+					args = jj.OpShow(msg.OperationId)
+				}
 				return m, func() tea.Msg {
-					output, _ := m.context.RunCommandImmediate(jj.OpShow(msg.OperationId))
-					return updatePreviewContentMsg{Content: string(output)}
+					output, _ := m.context.RunCommandImmediate(args)
+					return updatePreviewContentMsg{Tag: tag, Content: string(output)}
 				}
 			}
 		}
 	case tea.KeyMsg:
 		switch {
+		case m.searching:
+			return m, m.updateSearch(msg)
+		case key.Matches(msg, m.keyMap.Preview.SideBySide):
+			config.Current.Preview.SideBySide = !config.Current.Preview.SideBySide
+			return m, common.SelectionChanged
+		case key.Matches(msg, m.keyMap.Preview.Format):
+			config.Current.Preview.CycleDiffFormat()
+			return m, common.SelectionChanged
+		case key.Matches(msg, m.keyMap.Preview.Whitespace):
+			config.Current.Preview.IgnoreWhitespace = !config.Current.Preview.IgnoreWhitespace
+			return m, common.SelectionChanged
+		case key.Matches(msg, m.keyMap.Preview.Context):
+			config.Current.Preview.CycleContextLines()
+			return m, common.SelectionChanged
+		case key.Matches(msg, m.keyMap.Preview.Search):
+			m.searching = true
+			m.searchStart = m.viewRange.start
+			m.input.SetValue(m.query)
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		case key.Matches(msg, m.keyMap.Preview.NextMatch):
+			m.jumpToMatch(m.match + 1)
+		case key.Matches(msg, m.keyMap.Preview.PrevMatch):
+			m.jumpToMatch(m.match - 1)
+		case key.Matches(msg, m.keyMap.Preview.ScrollLeft):
+			m.xOffset = max(m.xOffset-horizontalScrollStep, 0)
+		case key.Matches(msg, m.keyMap.Preview.ScrollRight):
+			m.xOffset = max(min(m.xOffset+horizontalScrollStep, lipgloss.Width(m.displayContent())-m.width+2), 0)
 		case key.Matches(msg, m.keyMap.Preview.ScrollDown):
 			if m.viewRange.end < m.contentLineCount {
 				m.viewRange.start++
@@ -131,24 +251,275 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 	return m, nil
 }
 
+// setContent keeps the position when the content belongs to the same item, e.g. after a refresh
+func (m *Model) setContent(content string) {
+	m.content = content
+	m.contentLineCount = strings.Count(m.content, "\n")
+	m.files = nil
+	if diff.External() == "" {
+		m.files = jj.ParseGitDiff(m.content)
+	}
+	m.renderedWidth = 0
+	m.matchedWidth = -1
+	item := m.context.SelectedItem()
+	if sameItem(m.item, item) {
+		m.scrollTo(m.viewRange.start)
+	} else {
+		m.reset()
+		m.xOffset = 0
+		m.match = 0
+	}
+	m.item = item
+}
+
+// sameItem ignores the commit ids so that rewritten revisions are considered the same
+func sameItem(a context.SelectedItem, b context.SelectedItem) bool {
+	switch a := a.(type) {
+	case nil:
+		return false
+	case context.SelectedRevision:
+		b, ok := b.(context.SelectedRevision)
+		return ok && a.ChangeId == b.ChangeId
+	case context.SelectedFile:
+		b, ok := b.(context.SelectedFile)
+		return ok && a.ChangeId == b.ChangeId && a.File == b.File
+	default:
+		return a.Equal(b)
+	}
+}
+
+// updateSearch finds the matches as the query is typed, cancelling goes back to where the search started
+func (m *Model) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keyMap.Cancel):
+		m.searching = false
+		m.input.Blur()
+		m.setQuery("")
+		m.scrollTo(m.searchStart)
+		return nil
+	case key.Matches(msg, m.keyMap.Apply):
+		m.searching = false
+		m.input.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if query := m.input.Value(); query != m.query {
+		m.setQuery(query)
+		m.jumpToMatch(m.firstMatchAfter(m.searchStart))
+	}
+	return cmd
+}
+
+func (m *Model) setQuery(query string) {
+	m.query = query
+	m.match = 0
+	m.matchedWidth = -1
+}
+
+// updateMatches finds the lines of the displayed content matching the query, ignoring case
+func (m *Model) updateMatches() {
+	if m.matchedWidth == m.width {
+		return
+	}
+	m.matchedWidth = m.width
+	m.matches = nil
+	if m.query != "" {
+		needle := strings.ToLower(m.query)
+		for i, line := range strings.Split(ansi.Strip(m.displayContent()), "\n") {
+			if strings.Contains(strings.ToLower(line), needle) {
+				m.matches = append(m.matches, i)
+			}
+		}
+	}
+	m.match = min(m.match, max(len(m.matches)-1, 0))
+}
+
+func (m *Model) firstMatchAfter(line int) int {
+	m.updateMatches()
+	for i, match := range m.matches {
+		if match >= line {
+			return i
+		}
+	}
+	return 0
+}
+
+// jumpToMatch scrolls the match into view, keeping a few lines of context above it
+func (m *Model) jumpToMatch(index int) {
+	m.updateMatches()
+	if len(m.matches) == 0 {
+		return
+	}
+	m.match = (index + len(m.matches)) % len(m.matches)
+	line := m.matches[m.match]
+	m.scrollTo(max(line-3, 0))
+
+	// scroll horizontally when the match is out of view
+	width := m.width - 2
+	text := strings.ToLower(strings.Split(ansi.Strip(m.displayContent()), "\n")[line])
+	if column := strings.Index(text, strings.ToLower(m.query)); column >= 0 {
+		start := ansi.StringWidth(text[:column])
+		end := start + ansi.StringWidth(m.query)
+		if start < m.xOffset || end > m.xOffset+width {
+			m.xOffset = max(end-width, 0)
+		}
+	}
+}
+
+func (m *Model) scrollTo(line int) {
+	m.viewRange.start = max(min(line, m.contentLineCount), 0)
+	m.viewRange.end = min(m.viewRange.start+m.height-3, m.contentLineCount)
+}
+
+func (m *Model) statusView() string {
+	if m.searching {
+		return m.input.View()
+	}
+	if m.query == "" {
+		return ""
+	}
+	if len(m.matches) == 0 {
+		return common.DefaultPalette.Dimmed.Render(fmt.Sprintf("no matches for '%s'", m.query))
+	}
+	return common.DefaultPalette.Dimmed.Render(fmt.Sprintf("match %d/%d for '%s'", m.match+1, len(m.matches), m.query))
+}
+
+// loaded doesn't cache the output of failed commands
+func (m *Model) loaded(tag int, key cacheKey, output string, err error) tea.Msg {
+	if err != nil {
+		key = cacheKey{}
+	}
+	return updatePreviewContentMsg{Tag: tag, Key: key, Content: m.format(output)}
+}
+
+// cacheKey includes everything that changes the loaded content, the width only matters to the external formatter
+func (m *Model) cacheKey(commitId string, file string) cacheKey {
+	format := strings.Join(m.diffArgs(), " ")
+	if len(config.Current.Diff.Formatter) > 0 {
+		format += " " + strconv.Itoa(m.width-2)
+	}
+	return cacheKey{commitId: commitId, format: format, file: file}
+}
+
+// loadRefs loads the bookmarks and tags of the commits, as they are part of the previews but don't change the commit ids
+func (m *Model) loadRefs(commitIds []string) tea.Cmd {
+	if len(commitIds) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		output, err := m.context.RunCommandImmediate(jj.CommitRefs(commitIds))
+		if err != nil {
+			return nil
+		}
+		return refsLoadedMsg{Refs: jj.ParseCommitRefsOutput(string(output))}
+	}
+}
+
+// refsOf finds the bookmarks and tags of the commit, whose id can be shortened
+func (m *Model) refsOf(commitId string) string {
+	if refs, ok := m.refs[commitId]; ok {
+		return refs
+	}
+	for fullId, refs := range m.refs {
+		if strings.HasPrefix(fullId, commitId) {
+			return refs
+		}
+	}
+	return ""
+}
+
+// prefetchNeighbours loads the previews of the revisions around the selected one in the background
+func (m *Model) prefetchNeighbours() tea.Cmd {
+	if _, ok := m.context.SelectedItem().(context.SelectedRevision); !ok {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, commitId := range m.prefetch {
+		key := m.cacheKey(commitId, "")
+		if _, ok := m.cache.get(key); ok {
+			continue
+		}
+		cmds = append(cmds, func() tea.Msg {
+			output, err := m.context.RunCommandImmediate(jj.Show(commitId, m.diffArgs()...))
+			if err != nil {
+				return nil
+			}
+			return cachePreviewContentMsg{Key: key, Content: m.format(string(output))}
+		})
+	}
+	m.prefetch = nil
+	return tea.Batch(cmds...)
+}
+
+// diffArgs uses the configured diff format, defaulting to git format when it's needed to lay out the hunks side by side
+func (m *Model) diffArgs() []string {
+	format := config.Current.Preview.DiffFormat
+	if format == "" && config.Current.Preview.SideBySide {
+		format = "git"
+	}
+	return jj.DiffArgs(format)
+}
+
+// format pipes the output through the external formatter, showing the formatter's error instead when it fails
+func (m *Model) format(output string) string {
+	formatted, err := diff.Format(m.context, output, m.width-2)
+	if err != nil {
+		return err.Error() + "\n" + formatted
+	}
+	return formatted
+}
+
+func (m *Model) displayContent() string {
+	width := m.width - 2
+	if !config.Current.Preview.SideBySide || !diff.CanRenderSideBySide(m.files, width) {
+		m.contentLineCount = strings.Count(m.content, "\n")
+		return m.content
+	}
+	if m.renderedWidth != width {
+		lines, _ := diff.RenderSideBySide(m.content, m.files, width)
+		m.rendered = strings.Join(lines, "\n")
+		m.renderedWidth = width
+	}
+	m.contentLineCount = strings.Count(m.rendered, "\n")
+	return m.rendered
+}
+
 func (m *Model) View() string {
+	m.updateMatches()
+	status := m.statusView()
+	height := m.height - 2
+	if status != "" {
+		height--
+	}
+
 	var w strings.Builder
-	scanner := bufio.NewScanner(strings.NewReader(m.content))
+	scanner := bufio.NewScanner(strings.NewReader(m.displayContent()))
 	current := 0
 	for scanner.Scan() {
 		line := scanner.Text()
-		if current >= m.viewRange.start && current <= m.viewRange.end {
+		if current >= m.viewRange.start && current < m.viewRange.start+height {
 			if current > m.viewRange.start {
 				w.WriteString("\n")
 			}
-			w.WriteString(lipgloss.NewStyle().MaxWidth(m.width - 2).Render(line))
+			if i, found := slices.BinarySearch(m.matches, current); found {
+				style := diff.MatchStyle
+				if i == m.match {
+					style = diff.CurrentMatchStyle
+				}
+				line = diff.Highlight(ansi.Strip(line), m.query, style)
+			}
+			w.WriteString(ansi.Cut(line, m.xOffset, m.xOffset+m.width-2))
 		}
 		current++
-		if current > m.viewRange.end {
+		if current >= m.viewRange.start+height {
 			break
 		}
 	}
-	view := lipgloss.Place(m.width-2, m.height-2, 0, 0, w.String())
+	view := lipgloss.Place(m.width-2, max(height, 0), 0, 0, w.String())
+	if status != "" {
+		view = lipgloss.JoinVertical(0, view, lipgloss.NewStyle().MaxWidth(m.width-2).Render(status))
+	}
 	return border.Render(view)
 }
 
@@ -158,8 +529,13 @@ func (m *Model) reset() {
 
 func New(context context.AppContext) Model {
 	keyMap := context.KeyMap()
+	input := textinput.New()
+	input.Prompt = "/"
+	input.PromptStyle = common.DefaultPalette.ChangeId
 	return Model{
 		viewRange: &viewRange{start: 0, end: 0},
+		cache:     newCache(cacheSize),
+		input:     input,
 		context:   context,
 		keyMap:    keyMap,
 		help:      help.New(),
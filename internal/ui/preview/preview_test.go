@@ -0,0 +1,230 @@
+package preview
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func selectRevision(m *Model, c *test.TestContext, changeId string, commitId string) tea.Cmd {
+	c.SetSelectedItem(context.SelectedRevision{ChangeId: changeId, CommitId: commitId})
+	m.Update(common.SelectionChangedMsg{})
+	_, cmd := m.Update(refreshPreviewContentMsg{Tag: m.tag})
+	return cmd
+}
+
+func show(revision string) []string {
+	return jj.Show(revision, (&Model{}).diffArgs()...)
+}
+
+func newModel(c *test.TestContext) *Model {
+	m := New(c)
+	m.SetWidth(80)
+	m.SetHeight(20)
+	return &m
+}
+
+func TestPreview_CachesContentByCommitId(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(show("abc")).SetOutput([]byte("content of abc"))
+	defer c.Verify()
+
+	model := newModel(c)
+	cmd := selectRevision(model, c, "abc", "111")
+	assert.NotNil(t, cmd)
+	model.Update(cmd())
+	assert.Contains(t, model.View(), "content of abc")
+
+	model.setContent("")
+	assert.Nil(t, selectRevision(model, c, "abc", "111"), "cached content is shown without running jj")
+	assert.Contains(t, model.View(), "content of abc")
+
+	model.Update(common.RevisionsLoadedMsg{CommitIds: []string{"222"}})
+	assert.NotNil(t, selectRevision(model, c, "abc", "111"), "rewritten commits are dropped from the cache")
+}
+
+func TestPreview_KeepsContentAfterRefresh(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(show("abc")).SetOutput([]byte("content of abc"))
+	c.Expect(jj.CommitRefs([]string{"111"})).SetOutput([]byte("111full;main;\n"))
+	defer c.Verify()
+
+	model := newModel(c)
+	model.Update(common.RevisionsLoadedMsg{CommitIds: []string{"111"}})
+	model.Update(model.loadRefs([]string{"111"})())
+	cmd := selectRevision(model, c, "abc", "111")
+	model.Update(cmd())
+
+	model.Update(common.RefreshMsg{})
+	_, cmd = model.Update(common.RevisionsLoadedMsg{CommitIds: []string{"111"}})
+	_, cmd = model.Update(cmd())
+	assert.Nil(t, cmd, "unchanged previews are kept")
+	assert.Nil(t, selectRevision(model, c, "abc", "111"))
+}
+
+func TestPreview_ReloadsContentWhenRefsChange(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(show("abc")).SetOutput([]byte("content of abc"))
+	c.Expect(jj.CommitRefs([]string{"111"})).SetOutput([]byte("111full;;\n"))
+	defer c.Verify()
+
+	model := newModel(c)
+	model.Update(model.loadRefs([]string{"111"})())
+	cmd := selectRevision(model, c, "abc", "111")
+	model.Update(cmd())
+
+	// a bookmark is set on the commit, which doesn't rewrite it
+	_, cmd = model.Update(refsLoadedMsg{Refs: map[string]string{"111full": "main;"}})
+	assert.NotNil(t, cmd, "the shown preview is loaded again")
+	assert.NotNil(t, selectRevision(model, c, "abc", "111"), "the bookmarks in the content have changed")
+}
+
+func TestPreview_CachesFileContentByCommitId(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.Diff("abc", "file.txt", (&Model{}).diffArgs()...)).SetOutput([]byte("diff of file.txt"))
+	defer c.Verify()
+
+	model := newModel(c)
+	c.SetSelectedItem(context.SelectedFile{ChangeId: "abc", CommitId: "111", File: "file.txt"})
+	model.Update(common.SelectionChangedMsg{})
+	_, cmd := model.Update(refreshPreviewContentMsg{Tag: model.tag})
+	assert.NotNil(t, cmd)
+	model.Update(cmd())
+
+	model.setContent("")
+	model.Update(common.SelectionChangedMsg{})
+	_, cmd = model.Update(refreshPreviewContentMsg{Tag: model.tag})
+	assert.Nil(t, cmd, "cached content is shown without running jj")
+	assert.Contains(t, model.View(), "diff of file.txt")
+}
+
+func TestPreview_PrefetchesNeighbours(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(show("abc")).SetOutput([]byte("content of abc"))
+	c.Expect(show("222")).SetOutput([]byte("content of the next revision"))
+	defer c.Verify()
+
+	model := newModel(c)
+	model.Update(common.PrefetchMsg{CommitIds: []string{"222"}})
+	cmd := selectRevision(model, c, "abc", "111")
+	_, prefetch := model.Update(cmd())
+	assert.NotNil(t, prefetch)
+	model.Update(prefetch())
+
+	assert.Nil(t, selectRevision(model, c, "def", "222"))
+	assert.Contains(t, model.View(), "content of the next revision")
+}
+
+func TestPreview_IgnoresContentOfPreviousSelection(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(show("abc")).SetOutput([]byte("content of abc"))
+	defer c.Verify()
+
+	model := newModel(c)
+	cmd := selectRevision(model, c, "abc", "111")
+	model.Update(common.SelectionChangedMsg{})
+	model.Update(cmd())
+	assert.NotContains(t, model.View(), "content of abc")
+
+	assert.Nil(t, selectRevision(model, c, "abc", "111"))
+	assert.Contains(t, model.View(), "content of abc")
+}
+
+func numberedLines(count int, width int) string {
+	var lines []string
+	for i := range count {
+		lines = append(lines, fmt.Sprintf("line %03d %s", i, strings.Repeat("x", width)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func loaded(t *testing.T, content string) (*test.TestContext, *Model) {
+	c := test.NewTestContext(t)
+	c.Expect(show("abc")).SetOutput([]byte(content))
+	model := newModel(c)
+	cmd := selectRevision(model, c, "abc", "111")
+	model.Update(cmd())
+	return c, model
+}
+
+func typeText(m *Model, text string) {
+	for _, r := range text {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestPreview_IncrementalSearch(t *testing.T) {
+	c, model := loaded(t, numberedLines(50, 10))
+	defer c.Verify()
+
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	assert.True(t, model.IsFocused())
+	typeText(model, "line 04")
+	assert.Equal(t, []int{40, 41, 42, 43, 44, 45, 46, 47, 48, 49}, model.matches)
+	assert.Equal(t, 37, model.viewRange.start)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, model.IsFocused())
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n"), Alt: true})
+	assert.Equal(t, 1, model.match)
+	assert.Contains(t, model.View(), "match 2/10 for 'line 04'")
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p"), Alt: true})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p"), Alt: true})
+	assert.Equal(t, 9, model.match, "wraps around to the last match")
+}
+
+func TestPreview_CancellingSearchGoesBack(t *testing.T) {
+	c, model := loaded(t, numberedLines(50, 10))
+	defer c.Verify()
+
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	typeText(model, "line 030")
+	assert.Equal(t, 27, model.viewRange.start)
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, 0, model.viewRange.start)
+	assert.Empty(t, model.query)
+}
+
+func TestPreview_ScrollsHorizontally(t *testing.T) {
+	c, model := loaded(t, numberedLines(5, 100))
+	defer c.Verify()
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRight, Alt: true})
+	assert.Equal(t, horizontalScrollStep, model.xOffset)
+	assert.NotContains(t, model.View(), "line 000")
+
+	for range 20 {
+		model.Update(tea.KeyMsg{Type: tea.KeyRight, Alt: true})
+	}
+	// stops when the end of the longest line is in view
+	assert.Equal(t, len("line 000 ")+100-78, model.xOffset)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyLeft, Alt: true})
+	assert.Equal(t, len("line 000 ")+100-78-horizontalScrollStep, model.xOffset)
+}
+
+func TestPreview_KeepsPositionWhenRevisionIsRewritten(t *testing.T) {
+	c, model := loaded(t, numberedLines(50, 100))
+	defer c.Verify()
+
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	model.Update(tea.KeyMsg{Type: tea.KeyRight, Alt: true})
+	start := model.viewRange.start
+	assert.NotZero(t, start)
+
+	cmd := selectRevision(model, c, "abc", "222")
+	model.Update(cmd())
+	assert.Equal(t, start, model.viewRange.start)
+	assert.Equal(t, horizontalScrollStep, model.xOffset)
+
+	cmd = selectRevision(model, c, "def", "333")
+	assert.NotNil(t, cmd)
+}
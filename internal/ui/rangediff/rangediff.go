@@ -0,0 +1,99 @@
+package rangediff
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+)
+
+// Show opens the range diff of the stack between trunk() and the revision in the diff viewer.
+// Each change is compared with its previous version in the evolog, or with its state at the operation when it's set.
+func Show(context context.AppContext, revision string, operationId string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := context.RunCommandImmediate(jj.StackLog(revision))
+		if err != nil {
+			return common.CommandCompletedMsg{Output: string(output), Err: err}
+		}
+		stack := jj.ParseStackOutput(string(output))
+		if len(stack) == 0 {
+			return common.CommandCompletedMsg{Err: fmt.Errorf("there are no changes between trunk() and %s", revision)}
+		}
+
+		var pairs []jj.RangeDiffPair
+		if operationId != "" {
+			changeIds := make([]string, len(stack))
+			for i, entry := range stack {
+				changeIds[i] = entry.FullChangeId
+			}
+			output, err := context.RunCommandImmediate(jj.StackAtOp(operationId, changeIds))
+			if err != nil {
+				return common.CommandCompletedMsg{Output: string(output), Err: err}
+			}
+			pairs = jj.PairWithPrevious(stack, jj.ParseStackOutput(string(output)))
+		} else {
+			pairs = jj.PairWithPrevious(stack, nil)
+			for i := range pairs {
+				if pairs[i].Divergent {
+					continue
+				}
+				// the commit is looked up rather than the change, whose id can be ambiguous
+				if output, err := context.RunCommandImmediate(jj.Predecessor(pairs[i].NewCommitId)); err == nil {
+					pairs[i].Old, pairs[i].OldCommitId = jj.ParsePredecessor(string(output))
+				}
+			}
+		}
+		return common.ShowDiffMsg(render(context, pairs))
+	}
+}
+
+// render lists the pairs with their interdiffs, unchanged changes are collapsed to their header
+func render(context context.AppContext, pairs []jj.RangeDiffPair) string {
+	var b strings.Builder
+	for _, pair := range pairs {
+		var interdiff string
+		status := "new"
+		switch {
+		case pair.Divergent:
+			status = "divergent"
+		case pair.OldCommitId == "":
+		case pair.Unchanged():
+			status = "unchanged"
+		default:
+			output, err := context.RunCommandImmediate(jj.Interdiff(pair.OldCommitId, pair.NewCommitId))
+			interdiff = strings.TrimSpace(string(output))
+			status = "changed"
+			if err != nil {
+				status = "interdiff failed"
+			} else if interdiff == "" {
+				status = "unchanged, rebased"
+			}
+		}
+		b.WriteString(renderHeader(pair, status))
+		b.WriteString("\n")
+		if interdiff != "" {
+			b.WriteString(interdiff)
+			b.WriteString("\n\n")
+		}
+	}
+	return b.String()
+}
+
+func renderHeader(pair jj.RangeDiffPair, status string) string {
+	old := pair.Old
+	if old == "" {
+		old = strings.Repeat("-", len(pair.New))
+	}
+	statusStyle := common.DefaultPalette.Dimmed
+	if status == "changed" {
+		statusStyle = common.DefaultPalette.Modified
+	}
+	return common.DefaultPalette.ChangeId.Render(pair.ChangeId) + " " +
+		common.DefaultPalette.CommitId.Render(old) + common.DefaultPalette.Dimmed.Render(" → ") +
+		common.DefaultPalette.CommitId.Render(pair.New) + " " +
+		common.DefaultPalette.Normal.Bold(true).Render(pair.Description) + " " +
+		statusStyle.Render("("+status+")")
+}
@@ -0,0 +1,41 @@
+package rangediff
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const stack = "kmtqprws;kmtqprwsxyzz;1a2b3c4d;1a2b3c4dfull;;first\nnzsvnmyx;nzsvnmyxkkpq;5e6f7a8b;5e6f7a8bfull;feature;second\n"
+
+func TestShow_ComparesWithPredecessors(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.StackLog("nzsvnmyx")).SetOutput([]byte(stack))
+	c.Expect(jj.Predecessor("1a2b3c4dfull")).SetOutput([]byte("1a2b3c4d;1a2b3c4dfull\n0a0a0a0a;0a0a0a0afull\n"))
+	c.Expect(jj.Predecessor("5e6f7a8bfull")).SetOutput([]byte("5e6f7a8b;5e6f7a8bfull\n0f0f0f0f;0f0f0f0ffull\n"))
+	c.Expect(jj.Interdiff("0a0a0a0afull", "1a2b3c4dfull"))
+	c.Expect(jj.Interdiff("0f0f0f0ffull", "5e6f7a8bfull")).SetOutput([]byte("diff --git a/main.go b/main.go\n"))
+	defer c.Verify()
+
+	msg := Show(c, "nzsvnmyx", "")()
+	output := ansi.Strip(string(msg.(common.ShowDiffMsg)))
+	assert.Contains(t, output, "kmtqprws 0a0a0a0a → 1a2b3c4d first (unchanged, rebased)\n")
+	assert.Contains(t, output, "nzsvnmyx 0f0f0f0f → 5e6f7a8b second (changed)\ndiff --git a/main.go b/main.go")
+}
+
+func TestShow_ComparesWithOperation(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.StackLog("@")).SetOutput([]byte(stack))
+	c.Expect(jj.StackAtOp("abc123", []string{"kmtqprwsxyzz", "nzsvnmyxkkpq"})).SetOutput([]byte("kmtqprws;kmtqprwsxyzz;1a2b3c4d1;1a2b3c4dfull;;first\n"))
+	defer c.Verify()
+
+	msg := Show(c, "@", "abc123")()
+	output := ansi.Strip(string(msg.(common.ShowDiffMsg)))
+	// the shortest ids differ between the operations
+	assert.Contains(t, output, "kmtqprws 1a2b3c4d1 → 1a2b3c4d first (unchanged)")
+	assert.Contains(t, output, "nzsvnmyx -------- → 5e6f7a8b second (new)")
+}
@@ -2,6 +2,8 @@ package revisions
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"slices"
 	"strings"
 
@@ -19,8 +21,10 @@ import (
 	"github.com/idursun/jjui/internal/ui/operations/bookmark"
 	"github.com/idursun/jjui/internal/ui/operations/details"
 	"github.com/idursun/jjui/internal/ui/operations/evolog"
+	"github.com/idursun/jjui/internal/ui/operations/filehistory"
 	"github.com/idursun/jjui/internal/ui/operations/rebase"
 	"github.com/idursun/jjui/internal/ui/operations/squash"
+	"github.com/idursun/jjui/internal/ui/rangediff"
 	"github.com/idursun/jjui/internal/ui/revset"
 )
 
@@ -44,11 +48,17 @@ type Model struct {
 	output      string
 	err         error
 	quickSearch string
+	// tags of the repository, nil until they are loaded
+	tags []jj.Tag
+	// revision whose details will be shown once the revisions are loaded
+	pendingDetails string
 }
 
 type updateRevisionsMsg struct {
 	rows             []graph.Row
 	selectedRevision string
+	// tags is nil unless they were reloaded
+	tags []jj.Tag
 }
 
 func (m *Model) IsFocused() bool {
@@ -115,6 +125,22 @@ func (m *Model) SelectedRevisions() []*jj.Commit {
 	return selected
 }
 
+// compare shows the differences between the marked revision and the revision at the cursor
+func (m *Model) compare() tea.Cmd {
+	current := m.SelectedRevision()
+	var marked []*jj.Commit
+	for _, row := range m.rows {
+		if row.IsSelected && row.Commit.GetChangeId() != current.GetChangeId() {
+			marked = append(marked, row.Commit)
+		}
+	}
+	if len(marked) != 1 {
+		err := errors.New("mark exactly one other revision to compare with")
+		return func() tea.Msg { return common.CommandCompletedMsg{Err: err} }
+	}
+	return common.ShowCompareDiff(marked[0].GetChangeId(), current.GetChangeId())
+}
+
 func (m *Model) Init() tea.Cmd {
 	return common.Refresh
 }
@@ -138,10 +164,52 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 		m.err = msg.Err
 		return m, nil
 	case common.RefreshMsg:
-		return m, m.load(m.revsetValue, msg.SelectedRevision)
+		return m, m.load(m.revsetValue, msg.SelectedRevision, msg.Tags || m.tags == nil)
+	case bookmark.StartMoveMsg:
+		op := bookmark.NewMoveBookmarkOperation(m.context, msg.Bookmark)
+		op.SetSelectedRevision(m.SelectedRevision())
+		m.op = op
+		return m, nil
+	case bookmark.MoveMsg:
+		return m, m.context.RunCommand(msg.Args, common.RefreshAndSelect(msg.Destination), common.Close)
+	case filehistory.StartMsg:
+		var cmd tea.Cmd
+		m.op, cmd = filehistory.NewOperation(m.context, msg.File, m.width, m.height)
+		return m, cmd
+	case details.ShowMsg:
+		if idx := m.selectRevision(msg.Revision); idx != -1 {
+			m.cursor = idx
+			return m, m.showDetails()
+		}
+		m.pendingDetails = msg.Revision
+		return m, m.load(m.revsetValue, msg.Revision, false)
 	case updateRevisionsMsg:
+		var cmds []tea.Cmd
+		if msg.tags != nil {
+			m.tags = msg.tags
+			var names []string
+			for _, tag := range m.tags {
+				names = append(names, tag.Name)
+			}
+			cmds = append(cmds, revset.UpdateTags(names))
+		}
+		commits := make([]*jj.Commit, 0, len(msg.rows))
+		for _, row := range msg.rows {
+			commits = append(commits, row.Commit)
+		}
+		jj.AttachTags(commits, m.tags)
 		m.updateGraphRows(msg.rows, msg.selectedRevision)
-		return m, m.highlightChanges
+		cmds = append(cmds, m.highlightChanges, common.RevisionsLoaded(m.commitIds()), m.updateSelection())
+		if pending := m.pendingDetails; pending != "" {
+			m.pendingDetails = ""
+			if idx := m.selectRevision(pending); idx != -1 && idx == m.cursor {
+				cmds = append(cmds, m.showDetails())
+			} else {
+				err := fmt.Errorf("%s is not in the current revset", pending)
+				cmds = append(cmds, func() tea.Msg { return common.CommandCompletedMsg{Err: err} })
+			}
+		}
+		return m, tea.Batch(cmds...)
 	}
 
 	if op, ok := m.op.(operations.OperationWithOverlay); ok {
@@ -178,7 +246,7 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 				m.viewRange = &viewRange{start: 0, end: 0}
 				return m, nil
 			case key.Matches(msg, m.keymap.Details.Mode):
-				m.op, cmd = details.NewOperation(m.context, m.SelectedRevision())
+				cmd = m.showDetails()
 			case key.Matches(msg, m.keymap.New):
 				selections := m.SelectedRevisions()
 				var changeIds []string
@@ -212,13 +280,13 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 			case key.Matches(msg, m.keymap.Evolog):
 				m.op, cmd = evolog.NewOperation(m.context, m.SelectedRevision().GetChangeId(), m.width, m.height)
 			case key.Matches(msg, m.keymap.Diff):
-				return m, func() tea.Msg {
-					changeId := m.SelectedRevision().GetChangeId()
-					output, _ := m.context.RunCommandImmediate(jj.Diff(changeId, ""))
-					return common.ShowDiffMsg(output)
-				}
+				return m, common.ShowRevisionDiff(m.SelectedRevision().GetChangeId(), "")
+			case key.Matches(msg, m.keymap.Compare):
+				return m, m.compare()
+			case key.Matches(msg, m.keymap.RangeDiff):
+				return m, rangediff.Show(m.context, m.SelectedRevision().GetChangeId(), "")
 			case key.Matches(msg, m.keymap.Refresh):
-				cmd = common.Refresh
+				cmd = common.RefreshTags
 			case key.Matches(msg, m.keymap.Squash):
 				m.op = squash.NewOperation(m.context, m.SelectedRevision().ChangeId)
 				if m.cursor < len(m.rows)-1 {
@@ -243,11 +311,36 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 
 func (m *Model) updateSelection() tea.Cmd {
 	if selectedRevision := m.SelectedRevision(); selectedRevision != nil {
-		return m.context.SetSelectedItem(context.SelectedRevision{ChangeId: selectedRevision.GetChangeId()})
+		cmd := m.context.SetSelectedItem(context.SelectedRevision{ChangeId: selectedRevision.GetChangeId(), CommitId: selectedRevision.CommitId})
+		if cmd == nil {
+			return nil
+		}
+		return tea.Batch(cmd, common.Prefetch(m.neighbours()))
 	}
 	return nil
 }
 
+// neighbours are the commits of the rows around the cursor
+func (m *Model) neighbours() []string {
+	var commitIds []string
+	for _, i := range []int{m.cursor + 1, m.cursor - 1} {
+		if i >= 0 && i < len(m.rows) && m.rows[i].Commit != nil {
+			commitIds = append(commitIds, m.rows[i].Commit.CommitId)
+		}
+	}
+	return commitIds
+}
+
+func (m *Model) commitIds() []string {
+	commitIds := make([]string, 0, len(m.rows))
+	for _, row := range m.rows {
+		if row.Commit != nil {
+			commitIds = append(commitIds, row.Commit.CommitId)
+		}
+	}
+	return commitIds
+}
+
 func (m *Model) highlightChanges() tea.Msg {
 	if m.err != nil || m.output == "" {
 		return nil
@@ -359,7 +452,8 @@ func (m *Model) View() string {
 	return normalStyle.MaxWidth(m.width).Render(content)
 }
 
-func (m *Model) load(revset string, selectedRevision string) tea.Cmd {
+// load reads the tags with a separate command, so they are only reloaded when asked for as they rarely change
+func (m *Model) load(revset string, selectedRevision string, loadTags bool) tea.Cmd {
 	return func() tea.Msg {
 		output, err := m.context.RunCommandImmediate(jj.Log(revset))
 		if err != nil {
@@ -369,16 +463,34 @@ func (m *Model) load(revset string, selectedRevision string) tea.Cmd {
 			}
 		}
 		rows := graph.ParseRows(bytes.NewReader(output))
-		return updateRevisionsMsg{rows, selectedRevision}
+		var tags []jj.Tag
+		if loadTags {
+			// tags are best effort; older versions of jj don't support templates in `jj tag list`
+			tags = []jj.Tag{}
+			if output, err := m.context.RunCommandImmediate(jj.TagList()); err == nil {
+				tags = append(tags, jj.ParseTagListOutput(string(output))...)
+			}
+		}
+		return updateRevisionsMsg{rows, selectedRevision, tags}
 	}
 }
 
+func (m *Model) showDetails() tea.Cmd {
+	var cmd tea.Cmd
+	m.op, cmd = details.NewOperation(m.context, m.SelectedRevision())
+	return cmd
+}
+
 func (m *Model) selectRevision(revision string) int {
 	idx := slices.IndexFunc(m.rows, func(row graph.Row) bool {
 		if revision == "@" {
 			return row.Commit.IsWorkingCopy
 		}
-		return row.Commit.GetChangeId() == revision || row.Commit.ChangeId == revision
+		if row.Commit.GetChangeId() == revision || row.Commit.ChangeId == revision {
+			return true
+		}
+		// full change ids match the (possibly) shortened change id displayed in the log
+		return len(revision) > len(row.Commit.ChangeId) && row.Commit.ChangeId != "" && strings.HasPrefix(revision, row.Commit.ChangeId)
 	})
 	return idx
 }
@@ -2,7 +2,9 @@ package revisions
 
 import (
 	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/graph"
+	"github.com/idursun/jjui/test"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -23,3 +25,37 @@ Parent commit      : nyqzpsmt 8b1e95e3 change third file
 	assert.False(t, model.rows[0].IsAffected)
 	assert.True(t, model.rows[1].IsAffected)
 }
+
+func TestModel_compareWithMarkedRevision(t *testing.T) {
+	model := Model{
+		rows: []graph.Row{
+			{Commit: &jj.Commit{ChangeId: "marked"}, IsSelected: true},
+			{Commit: &jj.Commit{ChangeId: "current"}},
+		},
+		cursor: 1,
+	}
+	assert.Equal(t, common.ShowRevisionDiffMsg{From: "marked", Revision: "current"}, model.compare()())
+
+	model.rows[0].IsSelected = false
+	msg := model.compare()().(common.CommandCompletedMsg)
+	assert.Error(t, msg.Err)
+}
+
+func TestModel_loadsTagsOnlyWhenAsked(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.Log("all()"))
+	c.Expect(jj.TagList()).SetOutput([]byte("v1.0;1111;abc;first\n"))
+	defer c.Verify()
+
+	model := New(c, "all()")
+	_, cmd := model.Update(common.RefreshMsg{})
+	msg := cmd().(updateRevisionsMsg)
+	assert.Len(t, msg.tags, 1)
+	model.Update(msg)
+
+	_, cmd = model.Update(common.RefreshMsg{})
+	assert.Nil(t, cmd().(updateRevisionsMsg).tags)
+
+	_, cmd = model.Update(common.RefreshMsg{Tags: true})
+	assert.Len(t, cmd().(updateRevisionsMsg).tags, 1)
+}
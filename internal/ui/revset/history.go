@@ -0,0 +1,134 @@
+package revset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxHistoryEntries limits the size of the history, the least recently used revsets are dropped first
+const maxHistoryEntries = 100
+
+type historyEntry struct {
+	Revset   string    `json:"revset"`
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// History keeps the submitted revsets of a repository. It's saved to the user cache dir
+// unless it has no path, e.g. when the cache dir is not available.
+type History struct {
+	path    string
+	entries []historyEntry
+}
+
+// LoadHistory reads the history of the repository at the location, a missing or broken history file is ignored
+func LoadHistory(location string) *History {
+	h := &History{}
+	if location == "" {
+		return h
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return h
+	}
+	sum := sha256.Sum256([]byte(location))
+	h.path = filepath.Join(cacheDir, "jjui", "revsets", hex.EncodeToString(sum[:8])+".json")
+	if data, err := os.ReadFile(h.path); err == nil {
+		_ = json.Unmarshal(data, &h.entries)
+	}
+	return h
+}
+
+// Add records a use of the revset
+func (h *History) Add(revset string, now time.Time) {
+	revset = strings.TrimSpace(revset)
+	if revset == "" {
+		return
+	}
+	if i := slices.IndexFunc(h.entries, func(e historyEntry) bool { return e.Revset == revset }); i != -1 {
+		h.entries[i].Count++
+		h.entries[i].LastUsed = now
+	} else {
+		h.entries = append(h.entries, historyEntry{Revset: revset, Count: 1, LastUsed: now})
+	}
+	if len(h.entries) > maxHistoryEntries {
+		slices.SortFunc(h.entries, func(a, b historyEntry) int { return b.LastUsed.Compare(a.LastUsed) })
+		h.entries = h.entries[:maxHistoryEntries]
+	}
+}
+
+// Save encodes the history and returns the function that writes it, so that the file can be written
+// in the background while the history keeps changing
+func (h *History) Save() func() error {
+	path := h.path
+	data, err := json.Marshal(h.entries)
+	return func() error {
+		if path == "" || err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		// written to a temporary file first so that a concurrent session never reads a partial file
+		tmp, err := os.CreateTemp(filepath.Dir(path), "revsets-*.json")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		if _, err := tmp.Write(data); err != nil {
+			tmp.Close()
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		return os.Rename(tmp.Name(), path)
+	}
+}
+
+// Search lists the revsets that fuzzy match the query, most frequently used first and the most recently used
+// among the equally frequent ones. An empty query lists all of them.
+func (h *History) Search(query string) []string {
+	var matches []historyEntry
+	for _, e := range h.entries {
+		if fuzzyMatch(e.Revset, query) {
+			matches = append(matches, e)
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b historyEntry) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return b.LastUsed.Compare(a.LastUsed)
+	})
+	return revsets(matches)
+}
+
+func revsets(entries []historyEntry) []string {
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.Revset)
+	}
+	return result
+}
+
+// fuzzyMatch reports whether the characters of the query appear in the revset in the same order, ignoring case
+func fuzzyMatch(revset string, query string) bool {
+	revset, query = strings.ToLower(revset), strings.ToLower(query)
+	for query != "" {
+		r, size := utf8.DecodeRuneInString(query)
+		i := strings.IndexRune(revset, r)
+		if i == -1 {
+			return false
+		}
+		revset, query = revset[i+utf8.RuneLen(r):], query[size:]
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package revset
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func newHistory(revsets ...string) *History {
+	h := &History{}
+	for i, revset := range revsets {
+		h.Add(revset, start.Add(time.Duration(i)*time.Minute))
+	}
+	return h
+}
+
+func TestHistory_Search(t *testing.T) {
+	h := newHistory("mine()", "trunk()..@", "mine()", "bookmarks()", "mine()", "trunk()..@", "all()")
+	assert.Equal(t, []string{"mine()", "trunk()..@", "all()", "bookmarks()"}, h.Search(""))
+	assert.Equal(t, []string{"trunk()..@", "bookmarks()"}, h.Search("rk"))
+	assert.Empty(t, h.Search("xyz"))
+}
+
+func TestHistory_Persisted(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	h := LoadHistory("/repo")
+	h.Add("mine()", start)
+	assert.NoError(t, h.Save()())
+
+	assert.Equal(t, []string{"mine()"}, LoadHistory("/repo").Search(""))
+	assert.Empty(t, LoadHistory("/other").Search(""))
+}
+
+func TestHistory_DropsLeastRecentlyUsed(t *testing.T) {
+	h := &History{}
+	for i := range maxHistoryEntries + 1 {
+		h.Add(string(rune('a'+i%26))+string(rune('0'+i/26)), start.Add(time.Duration(i)*time.Minute))
+	}
+	revsets := h.Search("")
+	assert.Len(t, revsets, maxHistoryEntries)
+	assert.NotContains(t, revsets, "a0")
+}
+
+func editing(history *History) Model {
+	model := New("", history)
+	model, _ = model.Update(EditRevSetMsg{Clear: true})
+	return model
+}
+
+func TestRecall(t *testing.T) {
+	model := editing(newHistory("mine()", "trunk()..@", "mine()", "all()"))
+	model.textInput.SetValue("draft")
+
+	// in the same order as the history search
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "mine()", model.textInput.Value())
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "trunk()..@", model.textInput.Value())
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, "draft", model.textInput.Value())
+}
+
+func TestRecall_KeepsSuggestionNavigation(t *testing.T) {
+	model := editing(newHistory("mine()"))
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	suggestion := model.textInput.CurrentSuggestion()
+	assert.NotEmpty(t, suggestion)
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, "au", model.textInput.Value())
+	assert.NotEqual(t, suggestion, model.textInput.CurrentSuggestion())
+}
+
+func TestReverseSearch(t *testing.T) {
+	model := editing(newHistory("mine()", "trunk()..@", "mine()"))
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("(")})
+	assert.Contains(t, model.View(), "mine()")
+	// the next match
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, model.searching)
+	assert.True(t, model.Editing)
+	assert.Equal(t, "trunk()..@", model.textInput.Value())
+}
+
+func TestSubmitAddsToHistory(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	history := LoadHistory("/repo")
+	model := New("default", history)
+	model, _ = model.Update(EditRevSetMsg{Clear: true})
+	model.textInput.SetValue("mine()")
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	// the history is saved by the command
+	assert.Empty(t, LoadHistory("/repo").Search(""))
+	for _, cmd := range cmd().(tea.BatchMsg) {
+		cmd()
+	}
+	assert.Equal(t, []string{"mine()"}, LoadHistory("/repo").Search(""))
+
+	model, _ = model.Update(EditRevSetMsg{Clear: true})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, []string{"mine()"}, history.Search(""))
+}
@@ -1,7 +1,9 @@
 package revset
 
 import (
+	"fmt"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -92,9 +94,18 @@ type Model struct {
 	Value         string
 	defaultRevSet string
 	signatureHelp string
+	tags          []string
 	textInput     textinput.Model
 	help          help.Model
 	keymap        keymap
+	history       *History
+	// historyIndex is the position in the history while stepping through it, -1 when not
+	historyIndex int
+	// draft is what was typed before recalling a revset from the history
+	draft       string
+	searching   bool
+	searchQuery string
+	searchIndex int
 }
 
 func (m Model) IsFocused() bool {
@@ -108,6 +119,8 @@ func (k keymap) ShortHelp() []key.Binding {
 		key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "complete")),
 		key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "next")),
 		key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "prev")),
+		key.NewBinding(key.WithKeys("up", "down"), key.WithHelp("up/down", "history")),
+		key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "search history")),
 		key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "accept")),
 		key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "quit")),
 	}
@@ -117,7 +130,11 @@ func (k keymap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{k.ShortHelp()}
 }
 
-func New(defaultRevSet string) Model {
+// New creates the revset editor, history can be nil to keep no history
+func New(defaultRevSet string, history *History) Model {
+	if history == nil {
+		history = &History{}
+	}
 	ti := textinput.New()
 	ti.Placeholder = ""
 	ti.Prompt = "revset: "
@@ -137,6 +154,8 @@ func New(defaultRevSet string) Model {
 		help:          h,
 		keymap:        keymap{},
 		textInput:     ti,
+		history:       history,
+		historyIndex:  -1,
 	}
 }
 
@@ -150,6 +169,9 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		if !m.Editing {
 			return m, nil
 		}
+		if m.searching {
+			return m.updateSearch(msg), nil
+		}
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
 			m.Editing = false
@@ -160,13 +182,32 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			if m.Value == "" {
 				m.Value = m.defaultRevSet
 			}
-			return m, tea.Batch(common.Close, UpdateRevSet(m.Value))
+			return m, tea.Batch(common.Close, UpdateRevSet(m.Value), m.addToHistory(m.Value))
+		case tea.KeyUp, tea.KeyDown:
+			// the suggestions are cycled with the same keys
+			if m.historyIndex != -1 || m.textInput.CurrentSuggestion() == "" {
+				if msg.Type == tea.KeyUp {
+					m.recall(1)
+				} else {
+					m.recall(-1)
+				}
+				return m, nil
+			}
+		case tea.KeyCtrlR:
+			m.searching = true
+			m.searchQuery = ""
+			m.searchIndex = 0
+			return m, nil
 		}
 	case UpdateRevSetMsg:
 		m.Editing = false
 		m.Value = string(msg)
+	case UpdateTagsMsg:
+		m.tags = msg
+		return m, nil
 	case EditRevSetMsg:
 		m.Editing = true
+		m.historyIndex = -1
 		m.signatureHelp = ""
 		m.textInput.Focus()
 		if msg.Clear {
@@ -197,15 +238,97 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 					suggestions = append(suggestions, value+rest)
 				}
 			}
+			for _, t := range m.tags {
+				if strings.HasPrefix(t, lastFunctionName) && t != lastFunctionName {
+					suggestions = append(suggestions, value+strings.TrimPrefix(t, lastFunctionName))
+				}
+			}
 		}
 	}
 	m.textInput.SetSuggestions(suggestions)
 
+	if _, ok := msg.(tea.KeyMsg); ok {
+		// editing the recalled revset makes it the draft
+		m.historyIndex = -1
+	}
 	var cmd tea.Cmd
 	m.textInput, cmd = m.textInput.Update(msg)
 	return m, cmd
 }
 
+// addToHistory records the submitted revset, the default revset is always a submit away so it's not recorded
+func (m Model) addToHistory(revset string) tea.Cmd {
+	if revset == m.defaultRevSet {
+		return nil
+	}
+	m.history.Add(revset, time.Now())
+	save := m.history.Save()
+	return func() tea.Msg {
+		if err := save(); err != nil {
+			return common.CommandCompletedMsg{Err: fmt.Errorf("failed to save the revset history: %w", err)}
+		}
+		return nil
+	}
+}
+
+// recall steps through the history in the same order as the history search, going past the first revset
+// brings back what was typed
+func (m *Model) recall(step int) {
+	entries := m.history.Search("")
+	if m.historyIndex == -1 {
+		m.draft = m.textInput.Value()
+	}
+	m.historyIndex = max(min(m.historyIndex+step, len(entries)-1), -1)
+	if m.historyIndex == -1 {
+		m.textInput.SetValue(m.draft)
+	} else {
+		m.textInput.SetValue(entries[m.historyIndex])
+	}
+	m.textInput.CursorEnd()
+}
+
+// updateSearch fuzzy matches the query against the history, repeating the search key moves to the next match
+func (m Model) updateSearch(msg tea.KeyMsg) Model {
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.searching = false
+	case tea.KeyEnter:
+		m.searching = false
+		if match := m.searchMatch(); match != "" {
+			m.textInput.SetValue(match)
+			m.textInput.CursorEnd()
+		}
+	case tea.KeyCtrlR:
+		m.searchIndex++
+	case tea.KeyBackspace:
+		if runes := []rune(m.searchQuery); len(runes) > 0 {
+			m.searchQuery = string(runes[:len(runes)-1])
+			m.searchIndex = 0
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.searchQuery += string(msg.Runes)
+		m.searchIndex = 0
+	}
+	return m
+}
+
+func (m Model) searchMatch() string {
+	matches := m.history.Search(m.searchQuery)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[m.searchIndex%len(matches)]
+}
+
+func (m Model) searchView() string {
+	query := common.DefaultPalette.ChangeId.Render("history search: ") + m.searchQuery
+	match := m.searchMatch()
+	if match == "" {
+		return lipgloss.JoinHorizontal(0, query, common.DefaultPalette.Dimmed.Render("  no matching revsets"))
+	}
+	return lipgloss.JoinHorizontal(0, query, common.DefaultPalette.Dimmed.Render("  → "), match)
+}
+
 var (
 	promptStyle = common.DefaultPalette.ChangeId.SetString("revset:")
 	cursorStyle = common.DefaultPalette.EmptyPlaceholder
@@ -213,6 +336,9 @@ var (
 
 func (m Model) View() string {
 	if m.Editing {
+		if m.searching {
+			return lipgloss.JoinVertical(0, m.searchView(), m.help.View(m.keymap))
+		}
 		if m.signatureHelp != "" {
 			return lipgloss.JoinVertical(0, m.textInput.View(), m.signatureHelp)
 		}
@@ -229,6 +355,15 @@ func (m Model) View() string {
 
 type UpdateRevSetMsg string
 
+// UpdateTagsMsg carries the tag names of the repository so that they can be suggested while editing the revset
+type UpdateTagsMsg []string
+
+func UpdateTags(tags []string) tea.Cmd {
+	return func() tea.Msg {
+		return UpdateTagsMsg(tags)
+	}
+}
+
 func UpdateRevSet(revset string) tea.Cmd {
 	return func() tea.Msg {
 		return UpdateRevSetMsg(revset)
@@ -17,7 +17,7 @@ func TestSignatureHelp(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.input, func(t *testing.T) {
-			model := New("")
+			model := New("", nil)
 			model.Editing = true
 			model.textInput.SetValue(test.input)
 			m, _ := model.Update(tea.KeyLeft)
@@ -40,7 +40,7 @@ func TestSuggestions(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.input, func(t *testing.T) {
-			model := New("")
+			model := New("", nil)
 			model.Editing = true
 			model.textInput.SetValue(test.input)
 			m, _ := model.Update(tea.KeyLeft)
@@ -49,3 +49,24 @@ func TestSuggestions(t *testing.T) {
 		})
 	}
 }
+
+func TestTagSuggestions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"tags(re", "tags(release"},
+		{"@ | re", "@ | release"},
+		{"::rel", "::release"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			model := New("", nil)
+			model, _ = model.Update(UpdateTagsMsg{"release", "v1"})
+			model.Editing = true
+			model.textInput.SetValue(test.input)
+			m, _ := model.Update(tea.KeyLeft)
+			assert.Contains(t, m.textInput.AvailableSuggestions(), test.expected)
+		})
+	}
+}
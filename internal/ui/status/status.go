@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/ui/common"
 	"github.com/idursun/jjui/internal/ui/context"
 )
@@ -135,6 +136,9 @@ func (m *Model) View() string {
 		commandStatusMark = m.help.View(m.keyMap)
 	}
 	ret := common.DefaultPalette.Normal.Render(m.command)
+	if created := jj.ParseCreatedBookmarks(m.output); m.error == nil && len(created) > 0 {
+		ret = lipgloss.JoinHorizontal(0, ret, common.DefaultPalette.Dimmed.Render(" created "), common.DefaultPalette.Added.Render(strings.Join(created, ", ")))
+	}
 	if m.editing {
 		commandStatusMark = ""
 		ret = m.input.View()
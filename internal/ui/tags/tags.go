@@ -0,0 +1,266 @@
+package tags
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/idursun/jjui/internal/config"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/internal/ui/common"
+	"github.com/idursun/jjui/internal/ui/context"
+)
+
+type updateTagsMsg struct {
+	tags     []jj.Tag
+	editable bool
+}
+
+type Model struct {
+	context  context.AppContext
+	keymap   config.KeyMappings[key.Binding]
+	current  *jj.Commit
+	tags     []jj.Tag
+	cursor   int
+	editable bool
+	creating bool
+	input    textinput.Model
+	invalid  string
+	width    int
+	height   int
+}
+
+func (m *Model) Width() int {
+	return m.width
+}
+
+func (m *Model) Height() int {
+	return m.height
+}
+
+func (m *Model) SetWidth(w int) {
+	maxWidth, minWidth := 80, 40
+	m.width = max(min(maxWidth, w-4), minWidth)
+}
+
+func (m *Model) SetHeight(h int) {
+	maxHeight, minHeight := 30, 10
+	m.height = max(min(maxHeight, h-4), minHeight)
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.load
+}
+
+func (m *Model) load() tea.Msg {
+	output, err := m.context.RunCommandImmediate(jj.TagList())
+	if err != nil {
+		return common.CommandCompletedMsg{Output: string(output), Err: err}
+	}
+	tags := jj.ParseTagListOutput(string(output))
+	// tags of the selected revision are shown at the top
+	slices.SortStableFunc(tags, func(a, b jj.Tag) int {
+		ia, ib := m.isCurrent(a), m.isCurrent(b)
+		if ia && !ib {
+			return -1
+		}
+		if !ia && ib {
+			return 1
+		}
+		return 0
+	})
+	// creating and deleting tags is only available in newer versions of jj
+	editable := false
+	if help, err := m.context.RunCommandImmediate(jj.TagHelp()); err == nil {
+		editable = jj.SupportsTagEditing(string(help))
+	}
+	return updateTagsMsg{tags: tags, editable: editable}
+}
+
+func (m *Model) isCurrent(tag jj.Tag) bool {
+	return m.current != nil && slices.Contains(m.current.Tags, tag.Name)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.creating {
+		return m.updateCreate(msg)
+	}
+	switch msg := msg.(type) {
+	case updateTagsMsg:
+		m.tags = msg.tags
+		m.editable = msg.editable
+		m.cursor = 0
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keymap.Cancel):
+			return m, common.Close
+		case key.Matches(msg, m.keymap.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, m.keymap.Down):
+			if m.cursor < len(m.tags)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, m.keymap.Apply):
+			if m.cursor >= len(m.tags) || m.tags[m.cursor].ChangeId == "" {
+				return m, nil
+			}
+			return m, tea.Batch(common.Close, common.RefreshAndSelect(m.tags[m.cursor].ChangeId))
+		case key.Matches(msg, m.keymap.Tag.Create) && m.editable && m.current != nil:
+			m.creating = true
+			m.input.SetValue("")
+			m.invalid = ""
+			return m, m.input.Focus()
+		case key.Matches(msg, m.keymap.Tag.Delete) && m.editable:
+			if m.cursor >= len(m.tags) {
+				return m, nil
+			}
+			return m, m.context.RunCommand(jj.TagDelete(m.tags[m.cursor].Name), common.RefreshTags, common.Close)
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) updateCreate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keymap.Cancel):
+			m.creating = false
+			m.input.Blur()
+			return m, nil
+		case key.Matches(msg, m.keymap.Apply):
+			name := strings.TrimSpace(m.input.Value())
+			if m.invalid = m.validateName(name); m.invalid != "" {
+				return m, nil
+			}
+			return m, m.context.RunCommand(jj.TagSet(m.current.GetChangeId(), name), common.RefreshTags, common.Close)
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.invalid = m.validateName(strings.TrimSpace(m.input.Value()))
+	return m, cmd
+}
+
+func (m *Model) validateName(name string) string {
+	switch {
+	case name == "":
+		return "name cannot be empty"
+	case slices.ContainsFunc(m.tags, func(t jj.Tag) bool { return t.Name == name }):
+		return fmt.Sprintf("'%s' already exists", name)
+	case strings.IndexFunc(name, unicode.IsSpace) != -1,
+		strings.ContainsAny(name, "~^:?*[\\"),
+		strings.Contains(name, ".."),
+		strings.Contains(name, "@{"),
+		strings.HasPrefix(name, "-"),
+		strings.HasSuffix(name, ".lock"):
+		return fmt.Sprintf("'%s' is not a valid tag name", name)
+	}
+	return ""
+}
+
+var (
+	titleStyle       = lipgloss.NewStyle().Bold(true).Foreground(common.Magenta).PaddingLeft(2)
+	filterStyle      = common.DefaultPalette.ChangeId.PaddingLeft(2)
+	filterValueStyle = common.DefaultPalette.Normal.Bold(true)
+)
+
+func (m *Model) View() string {
+	title := titleStyle.Render("Tags")
+	subtitle := filterStyle.Render("Showing all")
+	if m.current != nil {
+		subtitle = lipgloss.JoinHorizontal(0, filterStyle.Render("Selected revision "), filterValueStyle.Render(m.current.GetChangeId()))
+	}
+	listView := m.listView(m.Width()-4, m.Height()-6)
+	helpView := m.helpView()
+	if m.creating {
+		validation := common.DefaultPalette.StatusSuccess.Render("  ✓")
+		if m.invalid != "" {
+			validation = common.DefaultPalette.StatusError.Render("  ✗ " + m.invalid)
+		}
+		listView = lipgloss.JoinVertical(0, filterValueStyle.PaddingLeft(2).Render(fmt.Sprintf("create tag on %s", m.current.GetChangeId())), "", "  "+m.input.View(), validation)
+		helpView = " " + lipgloss.JoinHorizontal(0, renderKey(m.keymap.Apply), renderKey(m.keymap.Cancel))
+	}
+	content := lipgloss.JoinVertical(0, title, "", subtitle, "", listView, "", helpView)
+	content = lipgloss.Place(m.Width(), m.Height(), 0, 0, content)
+	return lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Render(content)
+}
+
+func (m *Model) listView(width int, height int) string {
+	if m.tags == nil {
+		return "  loading"
+	}
+	if len(m.tags) == 0 {
+		return common.DefaultPalette.Dimmed.Render("  no tags")
+	}
+	nameWidth := 0
+	for _, tag := range m.tags {
+		nameWidth = max(nameWidth, lipgloss.Width(tag.Name))
+	}
+	start := max(0, m.cursor-height+1)
+	end := min(len(m.tags), start+height)
+	var lines []string
+	for i := start; i < end; i++ {
+		tag := m.tags[i]
+		nameStyle := common.DefaultPalette.Normal
+		if m.isCurrent(tag) {
+			nameStyle = common.DefaultPalette.Added
+		}
+		target := common.DefaultPalette.CommitId.Render(fmt.Sprintf("%-8s", tag.CommitId))
+		if tag.CommitId == "" {
+			target = common.DefaultPalette.StatusError.Render("conflict")
+		}
+		line := lipgloss.JoinHorizontal(0,
+			"  ",
+			nameStyle.Render(fmt.Sprintf("%-*s", nameWidth, tag.Name)),
+			" ",
+			target,
+			" ",
+			strings.TrimSpace(tag.Description),
+		)
+		if i == m.cursor {
+			line = lipgloss.NewStyle().Bold(true).Background(common.IntenseBlack).Render(line)
+		}
+		lines = append(lines, lipgloss.NewStyle().MaxWidth(width).Render(line))
+	}
+	return lipgloss.JoinVertical(0, lines...)
+}
+
+func renderKey(k key.Binding) string {
+	if !k.Enabled() {
+		return ""
+	}
+	return lipgloss.JoinHorizontal(0, common.DefaultPalette.ChangeId.Render(k.Help().Key, ""), common.DefaultPalette.Dimmed.Render(k.Help().Desc, ""))
+}
+
+func (m *Model) helpView() string {
+	bindings := []string{
+		renderKey(key.NewBinding(key.WithKeys(m.keymap.Apply.Keys()...), key.WithHelp(m.keymap.Apply.Help().Key, "jump to target"))),
+	}
+	if m.editable {
+		bindings = append(bindings, renderKey(m.keymap.Tag.Create), renderKey(m.keymap.Tag.Delete))
+	}
+	bindings = append(bindings, renderKey(m.keymap.Cancel))
+	return " " + lipgloss.JoinHorizontal(0, bindings...)
+}
+
+func NewModel(c context.AppContext, current *jj.Commit, width int, height int) *Model {
+	ti := textinput.New()
+	ti.Prompt = "name: "
+	ti.PromptStyle = common.DefaultPalette.ChangeId
+	m := &Model{
+		context: c,
+		keymap:  c.KeyMap(),
+		current: current,
+		input:   ti,
+	}
+	m.SetWidth(width)
+	m.SetHeight(height)
+	return m
+}
@@ -0,0 +1,70 @@
+package tags
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/idursun/jjui/internal/jj"
+	"github.com/idursun/jjui/test"
+)
+
+const tagList = "v1.0;1a2b3c4d;kmtqprwsoxyz;release 1.0\nv1.1;5e6f7a8b;nzsvnmyxppwk;release 1.1\n"
+
+const tagHelp = "Usage: jj tag <COMMAND>\n\nCommands:\n  delete  Delete existing tags\n  list    List tags\n  set     Create or update tags\n"
+
+func TestCreateTag(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.TagList()).SetOutput([]byte(tagList))
+	c.Expect(jj.TagHelp()).SetOutput([]byte(tagHelp))
+	c.Expect(jj.TagSet("current", "v2.0"))
+	defer c.Verify()
+
+	tm := teatest.NewTestModel(t, test.NewShell(NewModel(c, &jj.Commit{ChangeId: "current"}, 80, 30)))
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("create tag"))
+	})
+	tm.Type("c")
+	tm.Type("v1.0")
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("'v1.0' already exists"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyCtrlU})
+	tm.Type("v2.0")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func TestDeleteTagOfSelectedRevision(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.TagList()).SetOutput([]byte(tagList))
+	c.Expect(jj.TagHelp()).SetOutput([]byte(tagHelp))
+	c.Expect(jj.TagDelete("v1.1"))
+	defer c.Verify()
+
+	current := &jj.Commit{ChangeId: "nzsvnmyx", Tags: []string{"v1.1"}}
+	tm := teatest.NewTestModel(t, test.NewShell(NewModel(c, current, 80, 30)))
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("delete tag"))
+	})
+	tm.Type("d")
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
+
+func TestEditingUnsupported(t *testing.T) {
+	c := test.NewTestContext(t)
+	c.Expect(jj.TagList()).SetOutput([]byte(tagList))
+	c.Expect(jj.TagHelp()).SetOutput([]byte("Usage: jj tag <COMMAND>\n\nCommands:\n  list  List tags\n"))
+	defer c.Verify()
+
+	tm := teatest.NewTestModel(t, test.NewShell(NewModel(c, &jj.Commit{ChangeId: "current"}, 80, 30)))
+	teatest.WaitFor(t, tm.Output(), func(bts []byte) bool {
+		return bytes.Contains(bts, []byte("jump to target"))
+	})
+	tm.Type("c")
+	tm.Type("d")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEsc})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(3*time.Second))
+}
@@ -7,13 +7,16 @@ import (
 	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/jj"
 	"github.com/idursun/jjui/internal/screen"
+	"github.com/idursun/jjui/internal/ui/annotate"
 	"github.com/idursun/jjui/internal/ui/bookmarks"
 	"github.com/idursun/jjui/internal/ui/context"
 	"github.com/idursun/jjui/internal/ui/git"
 	"github.com/idursun/jjui/internal/ui/helppage"
+	"github.com/idursun/jjui/internal/ui/hunks"
 	"github.com/idursun/jjui/internal/ui/oplog"
 	"github.com/idursun/jjui/internal/ui/preview"
 	"github.com/idursun/jjui/internal/ui/revset"
+	"github.com/idursun/jjui/internal/ui/tags"
 	"github.com/idursun/jjui/internal/ui/undo"
 
 	"github.com/idursun/jjui/internal/ui/common"
@@ -31,16 +34,20 @@ type Model struct {
 	revsetModel    revset.Model
 	previewModel   *preview.Model
 	previewVisible bool
-	diff           tea.Model
-	state          common.State
-	error          error
-	status         *status.Model
-	output         string
-	width          int
-	height         int
-	context        context.AppContext
-	keyMap         config.KeyMappings[key.Binding]
-	stacked        tea.Model
+	// previewMaximized temporarily gives the whole area to the preview
+	previewMaximized bool
+	diff             tea.Model
+	annotate         tea.Model
+	hunks            tea.Model
+	state            common.State
+	error            error
+	status           *status.Model
+	output           string
+	width            int
+	height           int
+	context          context.AppContext
+	keyMap           config.KeyMappings[key.Binding]
+	stacked          tea.Model
 }
 
 func (m Model) Init() tea.Cmd {
@@ -48,11 +55,19 @@ func (m Model) Init() tea.Cmd {
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if _, ok := msg.(common.CloseViewMsg); ok && (m.diff != nil || m.stacked != nil || m.oplog != nil) {
+	if _, ok := msg.(common.CloseViewMsg); ok && (m.diff != nil || m.annotate != nil || m.hunks != nil || m.stacked != nil || m.oplog != nil) {
 		if m.diff != nil {
 			m.diff = nil
 			return m, nil
 		}
+		if m.annotate != nil {
+			m.annotate = nil
+			return m, nil
+		}
+		if m.hunks != nil {
+			m.hunks = nil
+			return m, nil
+		}
 		m.stacked = nil
 		m.oplog = nil
 		return m, nil
@@ -64,6 +79,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
+	if m.annotate != nil {
+		m.annotate, cmd = m.annotate.Update(msg)
+		return m, cmd
+	}
+
+	if m.hunks != nil {
+		m.hunks, cmd = m.hunks.Update(msg)
+		return m, cmd
+	}
+
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -84,6 +109,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+		if m.oplog != nil && m.oplog.IsFocused() {
+			m.oplog, cmd = m.oplog.Update(msg)
+			return m, cmd
+		}
+
+		if m.previewVisible && m.previewModel.IsFocused() {
+			m.previewModel, cmd = m.previewModel.Update(msg)
+			return m, cmd
+		}
+
 		if m.stacked != nil {
 			m.stacked, cmd = m.stacked.Update(msg)
 			return m, cmd
@@ -102,22 +137,35 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.revsetModel, _ = m.revsetModel.Update(revset.EditRevSetMsg{Clear: m.state != common.Error})
 			return m, nil
 		case key.Matches(msg, m.keyMap.Git.Mode) && m.revisions.InNormalMode():
-			m.stacked = git.NewModel(m.context, m.revisions.SelectedRevision(), m.width, m.height)
-		case key.Matches(msg, m.keyMap.Undo) && m.revisions.InNormalMode():
+			m.stacked = git.NewModel(m.context, m.revisions.SelectedRevision(), m.revisions.SelectedRevisions(), m.width, m.height)
+		case key.Matches(msg, m.keyMap.Undo) && m.revisions.InNormalMode() && m.oplog == nil:
 			m.stacked = undo.NewModel(m.context)
 			cmds = append(cmds, m.stacked.Init())
 		case key.Matches(msg, m.keyMap.Bookmark.Mode) && m.revisions.InNormalMode():
 			m.stacked = bookmarks.NewModel(m.context, m.revisions.SelectedRevision(), m.width, m.height)
 			cmds = append(cmds, m.stacked.Init())
+		case key.Matches(msg, m.keyMap.Tag.Mode) && m.revisions.InNormalMode():
+			m.stacked = tags.NewModel(m.context, m.revisions.SelectedRevision(), m.width, m.height)
+			cmds = append(cmds, m.stacked.Init())
 		case key.Matches(msg, m.keyMap.Help):
 			cmds = append(cmds, common.ToggleHelp)
 			return m, tea.Batch(cmds...)
 		case key.Matches(msg, m.keyMap.Preview.Mode):
 			m.previewVisible = !m.previewVisible
+			m.previewMaximized = false
 			cmds = append(cmds, common.SelectionChanged)
 			return m, tea.Batch(cmds...)
-		case key.Matches(msg, m.keyMap.QuickSearch) && m.oplog != nil:
-			//HACK: prevents quick search from activating in op log view
+		case key.Matches(msg, m.keyMap.Preview.Maximize) && m.previewVisible:
+			m.previewMaximized = !m.previewMaximized
+			return m, nil
+		case key.Matches(msg, m.keyMap.Preview.Position) && m.previewVisible:
+			config.Current.Preview.TogglePosition()
+			return m, nil
+		case key.Matches(msg, m.keyMap.Preview.Grow) && m.previewVisible:
+			config.Current.Preview.Resize(1)
+			return m, nil
+		case key.Matches(msg, m.keyMap.Preview.Shrink) && m.previewVisible:
+			config.Current.Preview.Resize(-1)
 			return m, nil
 		}
 	case common.ToggleHelpMsg:
@@ -131,8 +179,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.stacked = nil
 		}
 		return m, nil
+	case common.ShowAnnotateMsg:
+		m.annotate = annotate.New(m.context, msg.Revision, msg.File, m.width, m.height)
+		return m, m.annotate.Init()
+	case hunks.StartMsg:
+		m.hunks = hunks.New(m.context, msg.Revision, msg.Files, msg.Mode, m.width, m.height)
+		return m, m.hunks.Init()
+	case common.ShowRevisionDiffMsg:
+		if msg.From != "" {
+			m.diff = diff.NewForRange(m.context, msg.From, msg.Revision, m.width, m.height)
+		} else {
+			m.diff = diff.NewForRevision(m.context, msg.Revision, msg.File, m.width, m.height)
+		}
+		return m, m.diff.Init()
 	case common.ShowDiffMsg:
-		m.diff = diff.New(string(msg), m.width, m.height)
+		m.diff = diff.New(m.context, string(msg), m.width, m.height)
 		return m, m.diff.Init()
 	case common.CommandCompletedMsg:
 		m.output = msg.Output
@@ -143,15 +204,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		leftWidth, leftHeight, previewWidth, previewHeight := m.layout(m.width, m.height-4)
+		m.revisions.SetWidth(leftWidth)
+		m.revisions.SetHeight(leftHeight)
 		if m.previewVisible {
-			m.revisions.SetWidth(m.width / 2)
-		} else {
-			m.revisions.SetWidth(m.width)
-		}
-		m.revisions.SetHeight(m.height - 4)
-		if m.previewVisible {
-			m.previewModel.SetWidth(m.width / 2)
-			m.previewModel.SetHeight(m.height - 4)
+			m.previewModel.SetWidth(previewWidth)
+			m.previewModel.SetHeight(previewHeight)
 		}
 		if s, ok := m.stacked.(common.Sizable); ok {
 			s.SetWidth(m.width - 2)
@@ -179,7 +237,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
-	if m.previewVisible {
+	if m.previewVisible || preview.UpdatesCache(msg) {
 		m.previewModel, cmd = m.previewModel.Update(msg)
 		cmds = append(cmds, cmd)
 	}
@@ -192,6 +250,13 @@ func (m Model) View() string {
 		return m.diff.View()
 	}
 
+	if m.annotate != nil {
+		return m.annotate.View()
+	}
+	if m.hunks != nil {
+		return m.hunks.View()
+	}
+
 	topView := m.revsetModel.View()
 	if m.state == common.Error {
 		topView += fmt.Sprintf("\n%s\n", m.output)
@@ -209,16 +274,23 @@ func (m Model) View() string {
 	footer := m.status.View()
 	footerHeight := lipgloss.Height(footer)
 
-	leftView := m.renderLeftView(footerHeight, topViewHeight)
+	leftWidth, leftHeight, previewWidth, previewHeight := m.layout(m.width, m.height-footerHeight-topViewHeight)
+	leftView := ""
+	if leftWidth > 0 && leftHeight > 0 {
+		leftView = m.renderLeftView(leftWidth, leftHeight)
+	}
 
 	previewView := ""
 	if m.previewVisible {
-		m.previewModel.SetWidth(m.width - lipgloss.Width(leftView))
-		m.previewModel.SetHeight(m.height - footerHeight - topViewHeight)
+		m.previewModel.SetWidth(previewWidth)
+		m.previewModel.SetHeight(previewHeight)
 		previewView = m.previewModel.View()
 	}
 
 	centerView := lipgloss.JoinHorizontal(lipgloss.Left, leftView, previewView)
+	if config.Current.Preview.Position == config.PreviewBottom {
+		centerView = lipgloss.JoinVertical(lipgloss.Left, leftView, previewView)
+	}
 
 	if m.stacked != nil {
 		stackedView := m.stacked.View()
@@ -230,24 +302,31 @@ func (m Model) View() string {
 	return lipgloss.JoinVertical(0, topView, centerView, footer)
 }
 
-func (m Model) renderLeftView(footerHeight int, topViewHeight int) string {
-	leftView := ""
-	w := m.width
-
-	if m.previewVisible {
-		w = m.width / 2
+// layout splits the area between the revisions (or the oplog) and the preview
+func (m Model) layout(width int, height int) (int, int, int, int) {
+	switch {
+	case !m.previewVisible:
+		return width, height, 0, 0
+	case m.previewMaximized:
+		return 0, 0, width, height
+	case config.Current.Preview.Position == config.PreviewBottom:
+		previewHeight := int(float64(height) * config.Current.Preview.SplitRatio())
+		return width, height - previewHeight, width, previewHeight
+	default:
+		previewWidth := int(float64(width) * config.Current.Preview.SplitRatio())
+		return width - previewWidth, height, previewWidth, height
 	}
+}
 
+func (m Model) renderLeftView(width int, height int) string {
 	if m.oplog != nil {
-		m.oplog.SetWidth(w)
-		m.oplog.SetHeight(m.height - footerHeight - topViewHeight)
-		leftView = m.oplog.View()
-	} else {
-		m.revisions.SetWidth(w)
-		m.revisions.SetHeight(m.height - footerHeight - topViewHeight)
-		leftView = m.revisions.View()
+		m.oplog.SetWidth(width)
+		m.oplog.SetHeight(height)
+		return m.oplog.View()
 	}
-	return leftView
+	m.revisions.SetWidth(width)
+	m.revisions.SetHeight(height)
+	return m.revisions.View()
 }
 
 func New(c context.AppContext, initialRevset string) tea.Model {
@@ -266,6 +345,6 @@ func New(c context.AppContext, initialRevset string) tea.Model {
 		previewModel:   &previewModel,
 		previewVisible: config.Current.Preview.ShowAtStart,
 		status:         &statusModel,
-		revsetModel:    revset.New(initialRevset),
+		revsetModel:    revset.New(initialRevset, revset.LoadHistory(c.Location())),
 	}
 }
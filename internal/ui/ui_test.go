@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/idursun/jjui/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModel_layout(t *testing.T) {
+	previous := config.Current.Preview
+	defer func() { config.Current.Preview = previous }()
+	config.Current.Preview.Ratio = 0.3
+
+	m := Model{}
+	assert.Equal(t, [4]int{100, 40, 0, 0}, layout(m, 100, 40), "the preview is hidden")
+
+	m.previewVisible = true
+	config.Current.Preview.Position = config.PreviewRight
+	assert.Equal(t, [4]int{70, 40, 30, 40}, layout(m, 100, 40))
+
+	config.Current.Preview.Position = config.PreviewBottom
+	assert.Equal(t, [4]int{100, 28, 100, 12}, layout(m, 100, 40))
+
+	m.previewMaximized = true
+	assert.Equal(t, [4]int{0, 0, 100, 40}, layout(m, 100, 40))
+}
+
+func layout(m Model, width int, height int) [4]int {
+	leftWidth, leftHeight, previewWidth, previewHeight := m.layout(width, height)
+	return [4]int{leftWidth, leftHeight, previewWidth, previewHeight}
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/idursun/jjui/internal/config"
 	"github.com/idursun/jjui/internal/ui/context"
+	"slices"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,6 +16,7 @@ import (
 type ExpectedCommand struct {
 	args   []string
 	output []byte
+	err    error
 	called bool
 }
 
@@ -22,6 +25,12 @@ func (e *ExpectedCommand) SetOutput(output []byte) *ExpectedCommand {
 	return e
 }
 
+// SetError makes the command fail with the error, along with its output
+func (e *ExpectedCommand) SetError(err error) *ExpectedCommand {
+	e.err = err
+	return e
+}
+
 type TestContext struct {
 	*testing.T
 	selectedItem context.SelectedItem
@@ -32,6 +41,10 @@ func (t *TestContext) KeyMap() config.KeyMappings[key.Binding] {
 	return config.Convert(config.DefaultKeyMappings)
 }
 
+func (t *TestContext) Location() string {
+	return ""
+}
+
 func (t *TestContext) SelectedItem() context.SelectedItem {
 	return t.selectedItem
 }
@@ -51,12 +64,12 @@ func (t *TestContext) RunCommandImmediate(args []string) ([]byte, error) {
 		assert.Fail(t, "unexpected command", subCommand)
 	}
 	for _, e := range expectations {
-		if assert.Equal(t.T, e.args, args) {
+		if slices.Equal(e.args, args) {
 			e.called = true
-			return e.output, nil
+			return e.output, e.err
 		}
 	}
-	assert.Fail(t, "unexpected command", subCommand)
+	assert.Fail(t, "unexpected command", strings.Join(args, " "))
 	return nil, nil
 }
 
@@ -74,6 +87,24 @@ func (t *TestContext) RunInteractiveCommand(args []string, continuation tea.Cmd)
 	return t.RunCommand(args, continuation)
 }
 
+func (t *TestContext) RunInteractiveCommandWithCleanup(args []string, cleanup func(), continuation tea.Cmd) tea.Cmd {
+	return t.RunCommand(args, func() tea.Msg {
+		cleanup()
+		return nil
+	}, continuation)
+}
+
+func (t *TestContext) RunFormatter(args []string, _ []byte) ([]byte, error) {
+	return t.RunCommandImmediate(args)
+}
+
+func (t *TestContext) RunPager(args []string, _ []byte) tea.Cmd {
+	return func() tea.Msg {
+		_, _ = t.RunCommandImmediate(args)
+		return nil
+	}
+}
+
 func (t *TestContext) Expect(args []string) *ExpectedCommand {
 	subCommand := args[0]
 	if _, ok := t.expectations[subCommand]; !ok {